@@ -0,0 +1,59 @@
+package ass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ASS 颜色，对应 &HAABBGGRR
+// A 为透明度，0 表示不透明，255 表示完全透明
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
+// 解析颜色值
+// 支持 &HAABBGGRR、&HBBGGRR&（样式覆盖中的写法）以及 [V4 Styles] 中的十进制写法
+func ParseColor(raw string) (Color, error) {
+	s := strings.TrimSpace(raw)
+	s = strings.TrimPrefix(s, "&")
+	s = strings.TrimSuffix(s, "&")
+
+	base := 10
+	if len(s) > 0 && (s[0] == 'H' || s[0] == 'h') {
+		s = s[1:]
+		base = 16
+	}
+	if s == "" || len(s) > 8 && base == 16 {
+		return Color{}, fmt.Errorf("%w: %q", ErrInvalidColor, raw)
+	}
+
+	value, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		// 十进制写法可能为负数，如 -2147483640
+		v, err := strconv.ParseInt(s, base, 32)
+		if err != nil {
+			return Color{}, fmt.Errorf("%w: %q", ErrInvalidColor, raw)
+		}
+		value = uint64(uint32(v))
+	}
+	return Color{
+		R: uint8(value),
+		G: uint8(value >> 8),
+		B: uint8(value >> 16),
+		A: uint8(value >> 24),
+	}, nil
+}
+
+// 返回样式中使用的 &HAABBGGRR 写法
+func (c Color) String() string {
+	return fmt.Sprintf("&H%02X%02X%02X%02X", c.A, c.B, c.G, c.R)
+}
+
+// 返回样式覆盖标签（\c 等）中使用的 &HBBGGRR& 写法，不包含透明度
+func (c Color) TagString() string {
+	return fmt.Sprintf("&H%02X%02X%02X&", c.B, c.G, c.R)
+}
@@ -0,0 +1,82 @@
+package ass
+
+import "fmt"
+
+// 诊断信息的严重程度
+type Severity uint8
+
+const (
+	SeverityInfo    Severity = iota // 提示
+	SeverityWarning                 // 警告，不影响解析结果
+	SeverityError                   // 错误，对应内容无法处理
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", uint8(s))
+	}
+}
+
+// 序列化为 JSON 等格式时使用名称
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// 诊断代码
+type DiagnosticCode string
+
+const (
+	CodeUnknownStyle      DiagnosticCode = "unknown-style"       // 引用了未定义的样式
+	CodeInvalidBold       DiagnosticCode = "invalid-bold"        // 不合法的字重值
+	CodeInvalidItalic     DiagnosticCode = "invalid-italic"      // 不合法的斜体值
+	CodeInvalidEvent      DiagnosticCode = "invalid-event"       // 无法处理的事件
+	CodeInvalidScriptInfo DiagnosticCode = "invalid-script-info" // [Script Info] 中不合法的值
+	CodeMalformedRow      DiagnosticCode = "malformed-row"       // 字段数与 Format 不一致的数据行
+)
+
+// 解析过程中产生的诊断信息
+type Diagnostic struct {
+	Severity Severity       // 严重程度
+	LineNum  uint           // 行号
+	Column   uint           // 列号（按字符计，从 1 开始），0 表示未知
+	Code     DiagnosticCode // 诊断代码
+	Message  string         // 诊断内容
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.LineNum, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// 记录一条诊断信息
+// ci 为 nil 时行号记为 0
+func (ap *ASSParser) addDiagnostic(ci *ContentInfo, column uint, severity Severity, code DiagnosticCode, format string, a ...any) {
+	d := Diagnostic{
+		Severity: severity,
+		Column:   column,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+	}
+	if ci != nil {
+		d.LineNum = ci.LineNum
+	}
+	ap.Diagnostics = append(ap.Diagnostics, d)
+}
+
+// 返回第一条达到 severity 级别的诊断信息
+func (ap *ASSParser) firstDiagnostic(severity Severity) (Diagnostic, bool) {
+	for _, d := range ap.Diagnostics {
+		if d.Severity >= severity {
+			return d, true
+		}
+	}
+	return Diagnostic{}, false
+}
+
+var _ error = Diagnostic{}
@@ -0,0 +1,333 @@
+package ass
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 嵌入在字幕中的文件（如 [Fonts] 区块中的字体）
+type EmbeddedFile struct {
+	Name string // 文件名
+	Data []byte // 解码后的文件内容
+}
+
+// 嵌入区块中单个文件对应的原始内容
+type embeddedBlock struct {
+	name    string        // 文件名
+	lineNum uint          // 文件名所在行号
+	raw     ContentInfo   // 文件名所在行的原始内容
+	lines   []ContentInfo // 编码后的数据行
+}
+
+// 将嵌入区块的内容按文件名拆分，第一个文件名之前存在数据时返回错误
+// keyword 为文件名所在行的前缀，如 "fontname:"
+func splitEmbeddedBlocks(contents []ContentInfo, keyword string) ([]embeddedBlock, error) {
+	blocks, stray := groupEmbeddedBlocks(contents, keyword)
+	if len(stray) > 0 {
+		return nil, fmt.Errorf("unexpected data at line %d: %w", stray[0].LineNum, ErrMissingFileName)
+	}
+	return blocks, nil
+}
+
+// 将嵌入区块的内容按文件名拆分，同时返回第一个文件名之前缺少文件名的数据行
+func groupEmbeddedBlocks(contents []ContentInfo, keyword string) ([]embeddedBlock, []ContentInfo) {
+	// fontname: DFHannotate-W7_400_0.ttc
+	// !!%!!!!1!1!!"!!!2V.61D[P2J5!!!01!!!"X%^4,T*AD7;=!!!"`!!!!&:D<7&QO9I9.1!!#>A!!!2A
+	blocks := make([]embeddedBlock, 0)
+	var stray []ContentInfo
+	for _, ci := range contents {
+		line := strings.TrimSpace(ci.RawContent)
+		switch {
+		case line == "":
+			continue
+		case startWith(line, keyword):
+			blocks = append(blocks, embeddedBlock{
+				name:    strings.TrimSpace(line[len(keyword):]),
+				lineNum: ci.LineNum,
+				raw:     ci,
+			})
+		case len(blocks) == 0:
+			stray = append(stray, ci)
+		default:
+			blocks[len(blocks)-1].lines = append(blocks[len(blocks)-1].lines, ci)
+		}
+	}
+	return blocks, stray
+}
+
+// 解析嵌入区块的内容并解码
+func parseEmbeddedFiles(contents []ContentInfo, keyword string) ([]EmbeddedFile, error) {
+	blocks, err := splitEmbeddedBlocks(contents, keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]EmbeddedFile, 0, len(blocks))
+	for _, block := range blocks {
+		decoded, err := block.decode()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, EmbeddedFile{Name: block.name, Data: decoded})
+	}
+	return files, nil
+}
+
+// 解码嵌入文件的数据
+func (block *embeddedBlock) decode() ([]byte, error) {
+	var data []byte
+	for _, ci := range block.lines {
+		data = append(data, strings.TrimSpace(ci.RawContent)...)
+	}
+	decoded, err := UUDecode(data)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to decode embedded file "%s" at line %d: %w`, block.name, block.lineNum, err)
+	}
+	return decoded, nil
+}
+
+// 将嵌入文件写出到 dir 目录中，返回写出的文件路径
+func WriteEmbeddedFiles(files []EmbeddedFile, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		// 只保留文件名部分，避免写出到 dir 之外
+		name := filepath.Base(filepath.Clean("/" + file.Name))
+		if name == "/" || name == "." {
+			return nil, fmt.Errorf(`failed to write embedded file "%s": %w`, file.Name, ErrInvalidFileName)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, file.Data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write embedded file %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// 解码 [Fonts] 区块中已嵌入的字体
+func (ap *ASSParser) EmbeddedFonts() ([]EmbeddedFile, error) {
+	fonts, err := parseEmbeddedFiles(ap.fontsContents, "fontname:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded fonts: %w", err)
+	}
+	return fonts, nil
+}
+
+// 将已嵌入的字体写出到 dir 目录中，返回写出的文件路径
+func (ap *ASSParser) ExtractFonts(dir string) ([]string, error) {
+	fonts, err := ap.EmbeddedFonts()
+	if err != nil {
+		return nil, err
+	}
+	return WriteEmbeddedFiles(fonts, dir)
+}
+
+// 按合并策略写入 [Fonts] 区块，header 为区块标题行
+// 保留的原有字体按原始文本写入，第一个文件名之前缺少文件名的数据直接丢弃
+func (ap *ASSParser) writeFonts(lw *lineWriter, header ContentInfo, fontDatas map[string][]byte, c *writeConfig) error {
+	blocks, stray := groupEmbeddedBlocks(ap.fontsContents, "fontname:")
+
+	report := EmbedReport{}
+	for _, ci := range stray {
+		report.Malformed = append(report.Malformed, ci.LineNum)
+	}
+	keptBlocks := make([]embeddedBlock, 0, len(blocks))
+	skipped := make(map[string]struct{})
+	for _, block := range blocks {
+		_, conflict := fontDatas[block.name]
+		switch {
+		case c.fontPolicy == FontMergeDrop:
+			report.Dropped = append(report.Dropped, block.name)
+		case conflict && c.fontPolicy == FontMergeReplace:
+			report.Replaced = append(report.Replaced, block.name)
+		default:
+			if conflict { // FontMergeKeep 时同名的新字体不再嵌入
+				skipped[block.name] = struct{}{}
+			}
+			report.Kept = append(report.Kept, block.name)
+			keptBlocks = append(keptBlocks, block)
+		}
+	}
+
+	// 对字体名称进行排序以确保输出的确定性
+	fontNames := make([]string, 0, len(fontDatas))
+	for fontName := range fontDatas {
+		if _, ok := skipped[fontName]; ok {
+			report.Skipped = append(report.Skipped, fontName)
+			continue
+		}
+		fontNames = append(fontNames, fontName)
+	}
+	sort.Strings(fontNames)
+	sort.Strings(report.Skipped)
+	report.Added = fontNames
+
+	if c.report != nil {
+		*c.report = report
+	}
+	if len(keptBlocks) == 0 && len(fontNames) == 0 {
+		return nil // 没有需要嵌入的字体
+	}
+
+	lw.content(header)
+	if len(fontNames) == 0 && len(keptBlocks) == len(blocks) && len(stray) == 0 { // 原有字体全部保留且没有新字体时原样写入
+		for _, ci := range ap.fontsContents {
+			lw.content(ci)
+		}
+		return lw.err
+	}
+	for _, block := range keptBlocks {
+		lw.content(block.raw)
+		for _, ci := range block.lines {
+			lw.content(ci)
+		}
+	}
+	for _, fontName := range fontNames {
+		lines, err := embeddedFileLines("fontname:", fontName, fontDatas[fontName])
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			lw.line(line)
+		}
+	}
+	lw.line("")
+	return lw.err
+}
+
+// 返回嵌入文件的文件名行与编码后的数据行
+func embeddedFileLines(keyword string, name string, data []byte) ([]string, error) {
+	var buf strings.Builder
+	if err := UUEncode(data, &buf, true); err != nil {
+		return nil, err
+	}
+	lines := []string{keyword + " " + name}
+	for line := range strings.SplitSeq(buf.String(), "\n") {
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// 删除嵌入区块中指定文件名的文件，其余内容保持不变
+func removeEmbeddedBlock(contents []ContentInfo, keyword string, name string) ([]ContentInfo, bool) {
+	result := make([]ContentInfo, 0, len(contents))
+	removed, skip := false, false
+	for _, ci := range contents {
+		line := strings.TrimSpace(ci.RawContent)
+		if startWith(line, keyword) {
+			skip = strings.TrimSpace(line[len(keyword):]) == name
+			removed = removed || skip
+		}
+		if !skip {
+			result = append(result, ci)
+		}
+	}
+	return result, removed
+}
+
+// 解码 [Graphics] 区块中已嵌入的图片
+func (ap *ASSParser) EmbeddedGraphics() ([]EmbeddedFile, error) {
+	graphics, err := parseEmbeddedFiles(ap.graphicsContents, "filename:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse embedded graphics: %w", err)
+	}
+	return graphics, nil
+}
+
+// 根据文件名获取已嵌入的图片，存在同名图片时返回第一个
+func (ap *ASSParser) EmbeddedGraphic(name string) (*EmbeddedFile, error) {
+	graphics, err := ap.EmbeddedGraphics()
+	if err != nil {
+		return nil, err
+	}
+	for _, graphic := range graphics {
+		if graphic.Name == name {
+			return &graphic, nil
+		}
+	}
+	return nil, fmt.Errorf(`failed to get embedded graphic "%s": %w`, name, ErrFileNotFound)
+}
+
+// 将已嵌入的图片写出到 dir 目录中，返回写出的文件路径
+func (ap *ASSParser) ExtractGraphics(dir string) ([]string, error) {
+	graphics, err := ap.EmbeddedGraphics()
+	if err != nil {
+		return nil, err
+	}
+	return WriteEmbeddedFiles(graphics, dir)
+}
+
+// 将图片嵌入 [Graphics] 区块，已存在同名图片时在原位置替换，否则追加到区块末尾
+func (ap *ASSParser) AddGraphic(name string, data []byte) error {
+	if strings.TrimSpace(name) != name || name == "" || strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf(`failed to add embedded graphic "%s": %w`, name, ErrInvalidFileName)
+	}
+	lines, err := embeddedFileLines("filename:", name, data)
+	if err != nil {
+		return fmt.Errorf(`failed to add embedded graphic "%s": %w`, name, err)
+	}
+
+	contents := make([]ContentInfo, 0, len(ap.graphicsContents)+len(lines)+2)
+	replaced, skip := false, false
+	for _, ci := range ap.graphicsContents {
+		line := strings.TrimSpace(ci.RawContent)
+		if startWith(line, "filename:") {
+			skip = strings.TrimSpace(line[len("filename:"):]) == name
+			if skip && !replaced { // 在第一个同名图片的位置写入，之后的同名图片直接删除
+				for _, line := range lines {
+					contents = append(contents, ContentInfo{RawContent: line})
+				}
+				replaced = true
+			}
+		}
+		switch {
+		case !skip:
+			contents = append(contents, ci)
+		case line == "" && strings.TrimSpace(contents[len(contents)-1].RawContent) != "":
+			contents = append(contents, ci) // 保留与之后的图片分隔的空行
+		}
+	}
+	if !replaced {
+		if n := len(contents); n > 0 && strings.TrimSpace(contents[n-1].RawContent) != "" {
+			contents = append(contents, ContentInfo{}) // 与之前的图片以空行分隔
+		}
+		for _, line := range lines {
+			contents = append(contents, ContentInfo{RawContent: line})
+		}
+		contents = append(contents, ContentInfo{})
+	}
+	ap.graphicsContents = contents
+	return nil
+}
+
+// 删除 [Graphics] 区块中指定文件名的图片，返回是否存在该图片
+func (ap *ASSParser) RemoveGraphic(name string) bool {
+	contents, removed := removeEmbeddedBlock(ap.graphicsContents, "filename:", name)
+	ap.graphicsContents = contents
+	return removed
+}
+
+// 写入 [Graphics] 区块，header 为区块标题行，没有任何内容时不写入
+func (ap *ASSParser) writeGraphics(lw *lineWriter, header ContentInfo) error {
+	if !slices.ContainsFunc(ap.graphicsContents, func(ci ContentInfo) bool {
+		return strings.TrimSpace(ci.RawContent) != ""
+	}) {
+		return nil
+	}
+	lw.content(header)
+	for _, ci := range ap.graphicsContents {
+		lw.content(ci)
+	}
+	if strings.TrimSpace(ap.graphicsContents[len(ap.graphicsContents)-1].RawContent) != "" {
+		lw.line("") // 与之后的区块以空行分隔
+	}
+	return lw.err
+}
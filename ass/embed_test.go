@@ -0,0 +1,293 @@
+package ass_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const embeddedASSPath = "../test_case/ass/[Airota&VCB-Studio] BOCCHI THE ROCK! Re： [Ma10p_1080p][x265_flac].CHS.ass.test"
+
+func TestEmbeddedFonts(t *testing.T) {
+	file, err := os.Open(embeddedASSPath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	ap, err := ass.NewASSParser(file)
+	require.NoError(t, err)
+
+	fonts, err := ap.EmbeddedFonts()
+	require.NoError(t, err)
+	require.Len(t, fonts, 11)
+	require.Equal(t, "DFHannotate-W7_400_0.ttc", fonts[0].Name)
+	require.Equal(t, "汉仪正圆-65S_700_0.ttf", fonts[10].Name)
+
+	for _, font := range fonts { // 子集化后的字体均为单个 sfnt
+		require.True(t, bytes.HasPrefix(font.Data, []byte{0x00, 0x01, 0x00, 0x00}), font.Name)
+	}
+
+	dir := t.TempDir()
+	paths, err := ap.ExtractFonts(dir)
+	require.NoError(t, err)
+	require.Len(t, paths, len(fonts))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, fonts[i].Data, data)
+	}
+}
+
+func TestWriteEmbeddedFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := ass.WriteEmbeddedFiles([]ass.EmbeddedFile{{Name: "../../escape.ttf", Data: []byte("font")}}, dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "escape.ttf")}, paths)
+
+	_, err = ass.WriteEmbeddedFiles([]ass.EmbeddedFile{{Name: "", Data: []byte("font")}}, dir)
+	require.ErrorIs(t, err, ass.ErrInvalidFileName)
+}
+
+const fontsASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Fonts]
+fontname: a.ttf
+97&B
+
+fontname: b.ttf
+97&C
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,测试
+`
+
+func TestWriteWithEmbeddedFonts(t *testing.T) {
+	testCases := []struct {
+		name   string
+		policy ass.FontMergePolicy
+		expect []ass.EmbeddedFile
+		report ass.EmbedReport
+	}{
+		{
+			name:   "丢弃原有字体",
+			policy: ass.FontMergeDrop,
+			expect: []ass.EmbeddedFile{{Name: "b.ttf", Data: []byte("new")}, {Name: "c.ttf", Data: []byte("new")}},
+			report: ass.EmbedReport{Added: []string{"b.ttf", "c.ttf"}, Dropped: []string{"a.ttf", "b.ttf"}},
+		},
+		{
+			name:   "保留原有字体",
+			policy: ass.FontMergeKeep,
+			expect: []ass.EmbeddedFile{{Name: "a.ttf", Data: []byte("aaa")}, {Name: "b.ttf", Data: []byte("aab")}, {Name: "c.ttf", Data: []byte("new")}},
+			report: ass.EmbedReport{Added: []string{"c.ttf"}, Kept: []string{"a.ttf", "b.ttf"}, Skipped: []string{"b.ttf"}},
+		},
+		{
+			name:   "替换同名字体",
+			policy: ass.FontMergeReplace,
+			expect: []ass.EmbeddedFile{{Name: "a.ttf", Data: []byte("aaa")}, {Name: "b.ttf", Data: []byte("new")}, {Name: "c.ttf", Data: []byte("new")}},
+			report: ass.EmbedReport{Added: []string{"b.ttf", "c.ttf"}, Kept: []string{"a.ttf"}, Replaced: []string{"b.ttf"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(fontsASSContent))
+			require.NoError(t, err)
+
+			var (
+				buf    bytes.Buffer
+				report ass.EmbedReport
+			)
+			fontDatas := map[string][]byte{"b.ttf": []byte("new"), "c.ttf": []byte("new")}
+			err = ap.WriteWithEmbeddedFonts(fontDatas, &buf, ass.WithFontMergePolicy(tc.policy), ass.WithEmbedReport(&report))
+			require.NoError(t, err)
+			require.Equal(t, tc.report, report)
+
+			// 再次处理输出结果应能得到相同的嵌入字体
+			output, err := ass.NewASSParser(&buf)
+			require.NoError(t, err)
+			fonts, err := output.EmbeddedFonts()
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, fonts)
+		})
+	}
+}
+
+func TestWriteWithMalformedFonts(t *testing.T) {
+	// [Fonts] 中第一个文件名之前存在数据
+	content := strings.Replace(fontsASSContent, "[Fonts]\n", "[Fonts]\nGARBAGE\n", 1)
+	testCases := []struct {
+		policy ass.FontMergePolicy
+		expect []ass.EmbeddedFile
+	}{
+		{policy: ass.FontMergeDrop, expect: []ass.EmbeddedFile{}},
+		{policy: ass.FontMergeKeep, expect: []ass.EmbeddedFile{{Name: "a.ttf", Data: []byte("aaa")}, {Name: "b.ttf", Data: []byte("aab")}}},
+		{policy: ass.FontMergeReplace, expect: []ass.EmbeddedFile{{Name: "a.ttf", Data: []byte("aaa")}, {Name: "b.ttf", Data: []byte("aab")}}},
+	}
+	for _, tc := range testCases {
+		ap, err := ass.NewASSParser(strings.NewReader(content))
+		require.NoError(t, err)
+		var (
+			buf    bytes.Buffer
+			report ass.EmbedReport
+		)
+		require.NoError(t, ap.WriteWithEmbeddedFonts(nil, &buf, ass.WithFontMergePolicy(tc.policy), ass.WithEmbedReport(&report)))
+		require.Equal(t, []uint{9}, report.Malformed)
+		require.Contains(t, buf.String(), "[Events]")
+		require.NotContains(t, buf.String(), "GARBAGE")
+
+		output, err := ass.NewASSParser(&buf)
+		require.NoError(t, err)
+		fonts, err := output.EmbeddedFonts()
+		require.NoError(t, err)
+		require.Equal(t, tc.expect, fonts)
+	}
+}
+
+const graphicsASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Fonts]
+fontname: a.ttf
+97&B
+
+[Graphics]
+filename: logo.png
+97&B
+
+filename: mask.png
+97&C
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,测试
+`
+
+func TestEmbeddedGraphics(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(graphicsASSContent))
+	require.NoError(t, err)
+
+	graphics, err := ap.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Equal(t, []ass.EmbeddedFile{{Name: "logo.png", Data: []byte("aaa")}, {Name: "mask.png", Data: []byte("aab")}}, graphics)
+	graphic, err := ap.EmbeddedGraphic("mask.png")
+	require.NoError(t, err)
+	require.Equal(t, []byte("aab"), graphic.Data)
+	_, err = ap.EmbeddedGraphic("missing.png")
+	require.ErrorIs(t, err, ass.ErrFileNotFound)
+
+	// 未修改时原样写入
+	var buf bytes.Buffer
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, graphicsASSContent, buf.String())
+
+	require.ErrorIs(t, ap.AddGraphic("", []byte("new")), ass.ErrInvalidFileName)
+	require.NoError(t, ap.AddGraphic("logo.png", []byte("new")))
+	require.NoError(t, ap.AddGraphic("new.png", []byte("new")))
+	graphics, err = ap.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Equal(t, []string{"logo.png", "mask.png", "new.png"}, []string{graphics[0].Name, graphics[1].Name, graphics[2].Name}) // 同名图片在原位置替换
+	require.True(t, ap.RemoveGraphic("mask.png"))
+	require.False(t, ap.RemoveGraphic("mask.png"))
+
+	buf.Reset()
+	require.NoError(t, ap.WriteWithEmbeddedFonts(map[string][]byte{"b.ttf": []byte("new")}, &buf))
+	require.Contains(t, buf.String(), "\n[Graphics]\nfilename: logo.png\n")
+	output, err := ass.NewASSParser(&buf)
+	require.NoError(t, err)
+	graphics, err = output.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Equal(t, []ass.EmbeddedFile{{Name: "logo.png", Data: []byte("new")}, {Name: "new.png", Data: []byte("new")}}, graphics)
+	fonts, err := output.EmbeddedFonts()
+	require.NoError(t, err)
+	require.Equal(t, []ass.EmbeddedFile{{Name: "b.ttf", Data: []byte("new")}}, fonts)
+}
+
+// [Graphics] 位于 [Events] 之后，并且之后还有其他区块
+const graphicsAfterEventsASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,测试
+
+[Graphics]
+filename: logo.png
+97&B
+
+[Aegisub Extradata]
+Data: 1,_aegi_perspective_ambient_plane,e4.77;30.46|2.44;4.35|41.73;2.35|42.29;28.85
+`
+
+func TestEmbeddedGraphicsPosition(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(graphicsAfterEventsASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	// 未知区块结束 [Graphics] 区块
+	graphics, err := ap.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Equal(t, []ass.EmbeddedFile{{Name: "logo.png", Data: []byte("aaa")}}, graphics)
+
+	// 保持原有的位置
+	var buf bytes.Buffer
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, graphicsAfterEventsASSContent, buf.String())
+
+	// 原本没有 [Graphics] 区块时在 [Events] 之前插入
+	ap, err = ass.NewASSParser(strings.NewReader(parseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.AddGraphic("logo.png", []byte("aaa")))
+	buf.Reset()
+	require.NoError(t, ap.Write(&buf))
+	require.Contains(t, buf.String(), "\n[Graphics]\nfilename: logo.png\n97&B\n\n[Events]\n")
+}
+
+// 全大写的区块标题同样结束嵌入区块
+func TestEmbeddedUppercaseHeader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "[Fonts]之后", content: strings.Replace(fontsASSContent, "[Events]", "[EVENTS]", 1)},
+		{name: "[Graphics]之后", content: strings.Replace(strings.Replace(graphicsASSContent, "[Events]", "[EVENTS]", 1), "[Graphics]", "[GRAPHICS]", 1)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(tc.content))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			require.Equal(t, 1, ap.EventTable.Len())
+			_, err = ap.EmbeddedFonts()
+			require.NoError(t, err)
+			_, err = ap.EmbeddedGraphics()
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, ap.Write(&buf))
+			require.Equal(t, tc.content, buf.String())
+
+			// 流式读取同样能找到 [EVENTS] 区块
+			_, err = ass.StreamFontSets(strings.NewReader(tc.content))
+			require.NoError(t, err)
+		})
+	}
+}
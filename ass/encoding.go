@@ -0,0 +1,284 @@
+package ass
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// 字幕文件的文本编码
+type TextEncoding uint8
+
+const (
+	EncodingAuto     TextEncoding = iota // 自动检测（读取时）或原始编码（写入时）
+	EncodingUTF8                         // UTF-8
+	EncodingUTF16LE                      // UTF-16 LE
+	EncodingUTF16BE                      // UTF-16 BE
+	EncodingGBK                          // GBK（按 GB18030 解码）
+	EncodingBig5                         // Big5
+	EncodingShiftJIS                     // Shift-JIS
+)
+
+func (e TextEncoding) String() string {
+	switch e {
+	case EncodingAuto:
+		return "auto"
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingGBK:
+		return "GBK"
+	case EncodingBig5:
+		return "Big5"
+	case EncodingShiftJIS:
+		return "Shift-JIS"
+	}
+	return "unknown"
+}
+
+// 根据名称获取编码，不区分大小写，不支持的编码返回 false
+func ParseTextEncoding(name string) (TextEncoding, bool) {
+	switch strings.ToUpper(strings.ReplaceAll(name, "_", "-")) {
+	case "", "AUTO":
+		return EncodingAuto, true
+	case "UTF-8", "UTF8":
+		return EncodingUTF8, true
+	case "UTF-16LE", "UTF-16", "UTF16LE", "UTF16":
+		return EncodingUTF16LE, true
+	case "UTF-16BE", "UTF16BE":
+		return EncodingUTF16BE, true
+	case "GBK", "GB2312", "GB18030", "CP936":
+		return EncodingGBK, true
+	case "BIG5", "BIG-5", "CP950":
+		return EncodingBig5, true
+	case "SHIFT-JIS", "SHIFTJIS", "SJIS", "CP932":
+		return EncodingShiftJIS, true
+	}
+	return EncodingAuto, false
+}
+
+// 返回对应的编码器，UTF-8 返回 nil
+func (e TextEncoding) encoding() encoding.Encoding {
+	switch e {
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case EncodingGBK:
+		return simplifiedchinese.GB18030
+	case EncodingBig5:
+		return traditionalchinese.Big5
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS
+	}
+	return nil
+}
+
+// 各编码的 BOM
+var encodingBOMs = []struct {
+	enc TextEncoding
+	bom []byte
+}{
+	{EncodingUTF8, []byte{0xEF, 0xBB, 0xBF}},
+	{EncodingUTF16LE, []byte{0xFF, 0xFE}},
+	{EncodingUTF16BE, []byte{0xFE, 0xFF}},
+}
+
+// 返回编码的 BOM，只有 UTF-8 与 UTF-16 有 BOM，其他编码返回 nil
+func (e TextEncoding) bom() []byte {
+	for _, b := range encodingBOMs {
+		if b.enc == e {
+			return b.bom
+		}
+	}
+	return nil
+}
+
+// 检测编码时读取的最大字节数
+const sniffSize = 64 * 1024
+
+// 开头全部为 ASCII 时，为寻找非 ASCII 内容最多读取的字节数
+// 嵌入的 [Fonts] 与 [Graphics] 数据全部为 ASCII，可能位于 [Events] 之前并远大于 sniffSize
+const maxASCIIPrefix = 64 * 1024 * 1024
+
+// 检测输入的编码并返回解码为 UTF-8 的 reader
+// enc 不为 EncodingAuto 时使用指定的编码，只跳过与之匹配的 BOM
+// 返回实际使用的编码以及输入是否带有 BOM
+func decodeReader(reader io.Reader, enc TextEncoding) (io.Reader, TextEncoding, bool, error) {
+	br := bufio.NewReaderSize(reader, sniffSize)
+	head, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, enc, false, err
+	}
+
+	hasBOM := false
+	for _, b := range encodingBOMs {
+		if bytes.HasPrefix(head, b.bom) && (enc == EncodingAuto || enc == b.enc) {
+			enc, hasBOM = b.enc, true
+			head = head[len(b.bom):]
+			if _, err := br.Discard(len(b.bom)); err != nil {
+				return nil, enc, false, err
+			}
+			break
+		}
+	}
+	var decoded io.Reader = br
+	if enc == EncodingAuto {
+		enc = detectEncoding(head)
+		if enc == EncodingUTF8 && len(head) == sniffSize && firstNonASCII(head) < 0 {
+			var prefix []byte
+			if prefix, enc, err = sniffPastASCII(br); err != nil {
+				return nil, enc, false, err
+			}
+			decoded = io.MultiReader(bytes.NewReader(prefix), br)
+		}
+	}
+
+	if e := enc.encoding(); e != nil {
+		return transform.NewReader(decoded, e.NewDecoder()), enc, hasBOM, nil
+	}
+	return decoded, enc, hasBOM, nil
+}
+
+// 跳过开头的 ASCII 内容，根据第一个包含非 ASCII 字符的行之后的内容检测编码
+// ASCII 内容在各编码下相同，跳过的内容作为 prefix 返回，调用方需要在 br 之前重新读取
+func sniffPastASCII(br *bufio.Reader) ([]byte, TextEncoding, error) {
+	var prefix []byte
+	for len(prefix) < maxASCIIPrefix {
+		buf, err := br.Peek(sniffSize)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, EncodingAuto, err
+		}
+		n := len(buf)
+		if i := firstNonASCII(buf); i >= 0 {
+			n = bytes.LastIndexByte(buf[:i], '\n') + 1 // 从所在行的开头检测
+			if n == 0 {
+				return prefix, detectEncoding(buf), nil
+			}
+		} else if err == io.EOF {
+			break
+		}
+		prefix = append(prefix, buf[:n]...)
+		if _, err := br.Discard(n); err != nil {
+			return nil, EncodingAuto, err
+		}
+	}
+	return prefix, EncodingUTF8, nil
+}
+
+// 返回第一个非 ASCII 字节的位置，不存在时返回 -1
+func firstNonASCII(b []byte) int {
+	for i, c := range b {
+		if c >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return -1
+}
+
+// 根据内容推测没有 BOM 的文本的编码
+func detectEncoding(head []byte) TextEncoding {
+	if len(head) == 0 {
+		return EncodingUTF8
+	}
+
+	// UTF-16 的 ASCII 字符有一半字节为 0
+	var evenZeros, oddZeros int
+	for i, b := range head {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	switch {
+	case oddZeros > len(head)/4 && evenZeros < oddZeros/8:
+		return EncodingUTF16LE
+	case evenZeros > len(head)/4 && oddZeros < evenZeros/8:
+		return EncodingUTF16BE
+	}
+
+	if validUTF8Prefix(head) {
+		return EncodingUTF8
+	}
+
+	// 依次按各编码解码，选择得分最高的编码
+	best, bestScore := EncodingGBK, -1<<31
+	for _, enc := range []TextEncoding{EncodingGBK, EncodingBig5, EncodingShiftJIS} {
+		decoded, _, err := transform.Bytes(enc.encoding().NewDecoder(), head)
+		if err != nil {
+			continue
+		}
+		if score := encodingScore(string(decoded)); score > bestScore {
+			best, bestScore = enc, score
+		}
+	}
+	return best
+}
+
+// 是否为合法的 UTF-8，允许末尾存在被截断的字符
+func validUTF8Prefix(b []byte) bool {
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size <= 1 {
+			return len(b) < utf8.UTFMax && !utf8.FullRune(b)
+		}
+		b = b[size:]
+	}
+	return true
+}
+
+// 中日文中的常用字，正确解码的文本中这些字出现的频率远高于错误解码的文本
+const commonCJK = "的一是不了人我在有他这這个個们們来來到时時大地为為子中你说說生国國年着著就那和要她出也得里裡后後" +
+	"以会會家可下而过過天去能对對小多然于於心学學么麼之都好看起发發当當没沒成只如事把还還用第样樣道想作种種开開" +
+	"日本私君僕今何見言思行気"
+
+// 解码结果的得分，非法字符扣分，常用字与假名加分
+func encodingScore(s string) int {
+	score := 0
+	for _, r := range s {
+		switch {
+		case r == utf8.RuneError:
+			score -= 20
+		case r >= 0x3041 && r <= 0x30FA: // 平假名与片假名
+			score += 2
+		case strings.ContainsRune(commonCJK, r):
+			score += 3
+		case r >= 0xE000 && r <= 0xF8FF: // 私用区，通常是错误解码的结果
+			score -= 5
+		}
+	}
+	return score
+}
+
+// 返回按指定编码输出的 writer，UTF-8 时原样返回
+// 调用方需要在写入完成后关闭返回的 WriteCloser
+func encodeWriter(writer io.Writer, enc TextEncoding) io.WriteCloser {
+	if e := enc.encoding(); e != nil {
+		return transform.NewWriter(writer, e.NewEncoder())
+	}
+	return nopWriteCloser{writer}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
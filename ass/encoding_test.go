@@ -0,0 +1,158 @@
+package ass_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const encodingASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,%s,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,%s
+`
+
+func TestEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		enc      ass.TextEncoding
+		encoding encoding.Encoding
+		bom      bool
+		fontName string
+		text     string
+	}{
+		{name: "GBK", enc: ass.EncodingGBK, encoding: simplifiedchinese.GBK, fontName: "黑体", text: "我们{\\fn宋体}是不是在这里说过话的人，你还记得吗"},
+		{name: "Big5", enc: ass.EncodingBig5, encoding: traditionalchinese.Big5, fontName: "微軟正黑體", text: "我們{\\fn標楷體}是不是在這裡說過話的人，你還記得嗎"},
+		{name: "Shift-JIS", enc: ass.EncodingShiftJIS, encoding: japanese.ShiftJIS, fontName: "ＭＳ ゴシック", text: "私は{\\fnＭＳ 明朝}ここで君と話したことがあるのを覚えていますか"},
+		{name: "UTF-16LE", enc: ass.EncodingUTF16LE, encoding: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), bom: true, fontName: "黑体", text: "你好"},
+		{name: "UTF-16BE无BOM", enc: ass.EncodingUTF16BE, encoding: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), fontName: "黑体", text: "你好"},
+		{name: "UTF-8", enc: ass.EncodingUTF8, encoding: encoding.Nop, bom: true, fontName: "黑体", text: "你好"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := strings.Replace(strings.Replace(encodingASSContent, "%s", tc.fontName, 1), "%s", tc.text, 1)
+			if tc.bom {
+				content = "\uFEFF" + content
+			}
+			data, err := tc.encoding.NewEncoder().Bytes([]byte(content))
+			require.NoError(t, err)
+
+			ap, err := ass.NewASSParser(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.Equal(t, tc.enc, ap.Encoding())
+			require.NoError(t, ap.Parse())
+			require.Contains(t, ap.FontSets, ass.FontDesc{FontName: tc.fontName, Bold: 400})
+
+			// 按原始编码写出时与输入完全一致
+			var buf bytes.Buffer
+			require.NoError(t, ap.Write(&buf, ass.WithOutputEncoding(ass.EncodingAuto)))
+			require.True(t, bytes.Equal(data, buf.Bytes()))
+
+			// 默认输出 UTF-8
+			buf.Reset()
+			require.NoError(t, ap.Write(&buf))
+			require.Equal(t, content, buf.String())
+		})
+	}
+
+	// 指定编码时不进行检测
+	data, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(parseASSContent))
+	require.NoError(t, err)
+	ap, err := ass.NewASSParser(bytes.NewReader(data), ass.WithEncoding(ass.EncodingBig5))
+	require.NoError(t, err)
+	require.Equal(t, ass.EncodingBig5, ap.Encoding())
+}
+
+// 带 BOM 的字幕转换为其他编码时，只有 UTF-8 与 UTF-16 写入对应的 BOM
+func TestEncodingBOM(t *testing.T) {
+	content := strings.Replace(strings.Replace(encodingASSContent, "%s", "Arial", 1), "%s", "Hello", 1)
+	testCases := []struct {
+		name     string
+		enc      ass.TextEncoding
+		encoding encoding.Encoding
+		bom      []byte
+	}{
+		{name: "GBK", enc: ass.EncodingGBK, encoding: simplifiedchinese.GBK},
+		{name: "Big5", enc: ass.EncodingBig5, encoding: traditionalchinese.Big5},
+		{name: "Shift-JIS", enc: ass.EncodingShiftJIS, encoding: japanese.ShiftJIS},
+		{name: "UTF-16LE", enc: ass.EncodingUTF16LE, encoding: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), bom: []byte{0xFF, 0xFE}},
+		{name: "UTF-16BE", enc: ass.EncodingUTF16BE, encoding: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), bom: []byte{0xFE, 0xFF}},
+		{name: "UTF-8", enc: ass.EncodingUTF8, encoding: encoding.Nop, bom: []byte{0xEF, 0xBB, 0xBF}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := tc.encoding.NewEncoder().Bytes([]byte(content))
+			require.NoError(t, err)
+			expect := append(tc.bom, data...)
+
+			ap, err := ass.NewASSParser(strings.NewReader("\uFEFF" + content))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			var buf bytes.Buffer
+			require.NoError(t, ap.Write(&buf, ass.WithOutputEncoding(tc.enc)))
+			require.Equal(t, expect, buf.Bytes())
+
+			r, err := ass.NewReader(strings.NewReader("\uFEFF" + content))
+			require.NoError(t, err)
+			buf.Reset()
+			require.NoError(t, r.WriteWithEmbeddedFonts(nil, &buf, ass.WithOutputEncoding(tc.enc)))
+			require.Equal(t, expect, buf.Bytes())
+		})
+	}
+}
+
+// 开头的 ASCII 内容（如嵌入字体）超过检测窗口时，继续读取直到出现非 ASCII 内容
+func TestEncodingAfterLargeEmbeddedFonts(t *testing.T) {
+	var fonts strings.Builder
+	fonts.WriteString("[Fonts]\nfontname: font_0.ttf\n")
+	for fonts.Len() < 120*1024 {
+		fonts.WriteString(strings.Repeat("!", 80) + "\n")
+	}
+	fonts.WriteString("\n")
+	content := strings.Replace(strings.Replace(encodingASSContent, "%s", "Arial", 1), "%s", "你好", 1)
+	content = strings.Replace(content, "[Events]", fonts.String()+"[Events]", 1)
+
+	for _, tc := range []struct {
+		enc      ass.TextEncoding
+		encoding encoding.Encoding
+	}{
+		{enc: ass.EncodingGBK, encoding: simplifiedchinese.GBK},
+		{enc: ass.EncodingBig5, encoding: traditionalchinese.Big5},
+		{enc: ass.EncodingUTF8, encoding: encoding.Nop},
+	} {
+		t.Run(tc.enc.String(), func(t *testing.T) {
+			data, err := tc.encoding.NewEncoder().Bytes([]byte(content))
+			require.NoError(t, err)
+
+			ap, err := ass.NewASSParser(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.Equal(t, tc.enc, ap.Encoding())
+			require.NoError(t, ap.Parse())
+			require.Equal(t, ass.CodepointSet{'你': {}, '好': {}}, ap.FontSets[ass.FontDesc{FontName: "Arial", Bold: 400}])
+
+			var buf bytes.Buffer
+			require.NoError(t, ap.Write(&buf, ass.WithOutputEncoding(ass.EncodingAuto)))
+			require.True(t, bytes.Equal(data, buf.Bytes()))
+
+			stream, err := ass.StreamFontSets(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.Equal(t, ap.FontSets, stream.FontSets)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package ass
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// 导入样式时对同名样式的处理策略
+type StyleImportPolicy uint8
+
+const (
+	StyleImportReplace StyleImportPolicy = iota // 使用模板中的样式替换同名样式
+	StyleImportKeep                             // 保留原有的同名样式，不导入模板中的样式
+)
+
+// 导入样式的结果报告
+// 同名且内容不同的样式视为冲突，按策略记录在 Replaced 或 Skipped 中
+type ImportReport struct {
+	Added     []string // 新增的样式
+	Replaced  []string // 被模板中的样式替换的同名样式
+	Skipped   []string // 因存在同名样式而未导入的样式
+	Unchanged []string // 与原有的同名样式完全相同的样式
+	Resampled bool     // 是否按脚本分辨率缩放了导入的样式
+}
+
+// 从模板字幕中导入样式，字幕需要已调用过 Parse
+// 模板未调用过 Parse 时会自动解析，模板可以不包含任何事件
+// 默认导入模板中的全部样式，可以使用 WithImportStyles 指定样式名
+// 新样式追加到样式表末尾，同名样式按 WithStyleImportPolicy 指定的策略处理，替换时保持原有的位置
+// 使用 WithImportResample 且两者的脚本分辨率不同时，导入的样式按 Resample 的规则缩放
+// 导入后会重新统计 FontSets，存在无法解析的样式或模板中不存在指定的样式时不做任何修改并返回错误
+func (ap *ASSParser) ImportStyles(template *ASSParser, opts ...ImportOption) (*ImportReport, error) {
+	if !ap.parsed {
+		return nil, fmt.Errorf("failed to import styles: %w", ErrNotParsed)
+	}
+	if !template.parsed {
+		// 样式库通常只有样式，缺少事件时样式表已经建立
+		if err := template.Parse(); err != nil && !errors.Is(err, ErrEventParseFailed) {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+	}
+	c := &importConfig{policy: StyleImportReplace}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	names := c.names
+	if len(names) == 0 {
+		for _, si := range template.StyleTable.rows {
+			if !slices.Contains(names, si.Name()) {
+				names = append(names, si.Name())
+			}
+		}
+	}
+
+	report := &ImportReport{
+		Added:     make([]string, 0),
+		Replaced:  make([]string, 0),
+		Skipped:   make([]string, 0),
+		Unchanged: make([]string, 0),
+	}
+	var st *resampleState
+	srcX, srcY := template.ScriptInfo.PlayRes()
+	dstX, dstY := ap.ScriptInfo.PlayRes()
+	if c.resample && (srcX != dstX || srcY != dstY) {
+		st = newResampleState(srcX, srcY, dstX, dstY, c.aspect)
+		report.Resampled = true
+	}
+
+	styles := make([]*Style, 0, len(names))
+	for _, name := range names {
+		si := template.StyleTable.Get(name)
+		if si == nil {
+			return nil, fmt.Errorf("failed to import style \"%s\": %w", name, ErrStyleNotFound)
+		}
+		style, err := si.Style()
+		if err != nil {
+			return nil, fmt.Errorf("failed to import style \"%s\": %w", name, err)
+		}
+		if st != nil {
+			st.resampleStyle(style)
+		}
+		styles = append(styles, style)
+	}
+
+	// 检查完成后再修改，出错时字幕保持不变
+	for _, style := range styles {
+		origin := ap.StyleTable.Get(style.Name)
+		if origin == nil {
+			ap.StyleTable.Append(NewStyleInfo(style, ap.StyleTable.format()))
+			report.Added = append(report.Added, style.Name)
+			continue
+		}
+		if originStyle, err := origin.Style(); err == nil && sameStyle(originStyle, style) {
+			report.Unchanged = append(report.Unchanged, style.Name)
+			continue
+		}
+		if c.policy == StyleImportKeep {
+			report.Skipped = append(report.Skipped, style.Name)
+			continue
+		}
+		origin.SetStyle(style)
+		report.Replaced = append(report.Replaced, style.Name)
+	}
+	ap.CollectFontSets()
+	return report, nil
+}
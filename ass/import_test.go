@@ -0,0 +1,121 @@
+package ass_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const importASSContent = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,2,15,15,15,1
+Style: Sign,黑体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,8,15,15,15,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,对白
+`
+
+const importTemplateASSContent = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1280
+PlayResY: 720
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,宋体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+Style: Title,仿宋,30,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+func TestImportStyles(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []ass.ImportOption
+		report *ass.ImportReport
+		styles []string
+	}{
+		{
+			name: "替换同名样式",
+			report: &ass.ImportReport{
+				Added:     []string{"Title"},
+				Replaced:  []string{"Default", "Sign"},
+				Skipped:   []string{},
+				Unchanged: []string{},
+			},
+			styles: []string{
+				"Style: Default,宋体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1",
+				"Style: Sign,黑体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1",
+				"Style: Title,仿宋,30,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1",
+			},
+		},
+		{
+			name: "保留同名样式并缩放",
+			opts: []ass.ImportOption{ass.WithStyleImportPolicy(ass.StyleImportKeep), ass.WithImportResample(ass.AspectStretch)},
+			report: &ass.ImportReport{
+				Added:     []string{"Title"},
+				Replaced:  []string{},
+				Skipped:   []string{"Default"},
+				Unchanged: []string{"Sign"},
+				Resampled: true,
+			},
+			styles: []string{
+				"Style: Default,楷体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,2,15,15,15,1",
+				"Style: Sign,黑体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,8,15,15,15,1",
+				"Style: Title,仿宋,45,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,3,0,8,15,15,15,1",
+			},
+		},
+		{
+			name: "指定样式",
+			opts: []ass.ImportOption{ass.WithImportStyles("Title")},
+			report: &ass.ImportReport{
+				Added:     []string{"Title"},
+				Replaced:  []string{},
+				Skipped:   []string{},
+				Unchanged: []string{},
+			},
+			styles: []string{
+				"Style: Default,楷体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,2,15,15,15,1",
+				"Style: Sign,黑体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,8,15,15,15,1",
+				"Style: Title,仿宋,30,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(importASSContent))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			template, err := ass.NewASSParser(strings.NewReader(importTemplateASSContent))
+			require.NoError(t, err)
+
+			report, err := ap.ImportStyles(template, tc.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tc.report, report)
+
+			var b strings.Builder
+			require.NoError(t, ap.Write(&b))
+			require.Contains(t, b.String(), strings.Join(tc.styles, "\n")+"\n\n")
+		})
+	}
+
+	ap, err := ass.NewASSParser(strings.NewReader(importASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	template, err := ass.NewASSParser(strings.NewReader(importTemplateASSContent))
+	require.NoError(t, err)
+	_, err = ap.ImportStyles(template, ass.WithImportStyles("Title", "Missing"))
+	require.ErrorIs(t, err, ass.ErrStyleNotFound)
+	require.Equal(t, 2, ap.StyleTable.Len())
+}
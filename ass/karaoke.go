@@ -0,0 +1,163 @@
+package ass
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 卡拉 OK 音节的标签名，\K 与 \kf 相同
+var karaokeTagNames = []string{"k", "K", "kf", "ko"}
+
+// 卡拉 OK 的一个音节
+type Syllable struct {
+	Tag      *tags.Tag      // 卡拉 OK 标签（\k、\K、\kf 或 \ko），为 nil 表示第一个卡拉 OK 标签之前的内容
+	Start    Timestamp      // 绝对开始时间
+	End      Timestamp      // 绝对结束时间
+	Segments []tags.Segment // 音节的内容，第一个覆盖段以卡拉 OK 标签开头，之后为文本与其他样式覆盖段
+	joined   bool           // 第一个覆盖段与上一个音节的最后一个覆盖段原本是否为同一个覆盖段
+}
+
+// 创建新的音节，kind 为卡拉 OK 标签名，如 "k"、"kf"
+// 标签的时长在重新生成对话文本时根据 Start 与 End 计算
+func NewSyllable(kind string, start Timestamp, end Timestamp, text string) *Syllable {
+	tag := tags.NewTag(kind, "0")
+	return &Syllable{
+		Tag:   tag,
+		Start: start,
+		End:   end,
+		Segments: []tags.Segment{
+			{Block: &tags.Block{Tags: []*tags.Tag{tag}}},
+			{Text: text},
+		},
+	}
+}
+
+// 音节时长
+func (s *Syllable) Duration() Timestamp {
+	return s.End - s.Start
+}
+
+// 音节的文本，不含样式覆盖段，保留 \N 等转义字符
+func (s *Syllable) Text() string {
+	var b strings.Builder
+	for _, seg := range s.Segments {
+		if seg.Block == nil {
+			b.WriteString(seg.Text)
+		}
+	}
+	return b.String()
+}
+
+// 将对话文本拆分为卡拉 OK 音节，start 为对话的开始时间
+// 每个卡拉 OK 标签开始一个新音节，\kt 将之后音节的开始时间设为相对 start 的指定时间
+// 第一个卡拉 OK 标签之前的内容作为 Tag 为 nil 的音节返回
+func ParseKaraoke(text string, start Timestamp) []*Syllable {
+	syllables := make([]*Syllable, 0)
+	cursor := start // 下一个音节的开始时间
+	cur := &Syllable{Start: start, End: start}
+	for _, seg := range tags.ParseText(text) {
+		if seg.Block == nil {
+			cur.Segments = append(cur.Segments, seg)
+			continue
+		}
+
+		// 在卡拉 OK 标签处拆分覆盖段
+		block := &tags.Block{Tags: make([]*tags.Tag, 0)}
+		for _, tag := range seg.Block.Tags {
+			switch {
+			case tag.Is("kt"):
+				cursor = start + tagCentiseconds(tag)
+			case tag.Is(karaokeTagNames...):
+				joined := len(block.Tags) > 0
+				if joined {
+					cur.Segments = append(cur.Segments, tags.Segment{Block: block})
+				}
+				if cur.Tag != nil || len(cur.Segments) > 0 {
+					syllables = append(syllables, cur)
+				}
+				cur = &Syllable{Tag: tag, Start: cursor, joined: joined}
+				cursor += tagCentiseconds(tag)
+				cur.End = cursor
+				block = &tags.Block{Tags: make([]*tags.Tag, 0)}
+			}
+			block.Tags = append(block.Tags, tag)
+		}
+		cur.Segments = append(cur.Segments, tags.Segment{Block: block})
+	}
+	if cur.Tag != nil || len(cur.Segments) > 0 {
+		syllables = append(syllables, cur)
+	}
+	return syllables
+}
+
+// 根据音节重新生成对话文本，start 为对话的开始时间
+// 卡拉 OK 标签的时长按音节的 Start 与 End 更新，音节之间的空隙会插入空的 \k 音节
+// 与上一个音节重叠的部分会被截去，\kt 保持不变
+func JoinKaraoke(syllables []*Syllable, start Timestamp) string {
+	segments := make([]tags.Segment, 0)
+	cursor := start
+	for _, syl := range syllables {
+		joined := syl.joined
+		if syl.Tag != nil {
+			if syl.Start > cursor { // 填补空隙
+				gap := tags.NewTag("k", strconv.FormatInt(int64(syl.Start-cursor), 10))
+				segments = append(segments, tags.Segment{Block: &tags.Block{Tags: []*tags.Tag{gap}}})
+				cursor = syl.Start
+				joined = false
+			}
+			duration := max(0, syl.End-cursor)
+			if tagCentiseconds(syl.Tag) != duration {
+				syl.Tag.Args = []string{strconv.FormatInt(int64(duration), 10)}
+			}
+			cursor += duration
+		}
+
+		for i, seg := range syl.Segments {
+			last := len(segments) - 1
+			if i == 0 && joined && seg.Block != nil && last >= 0 && segments[last].Block != nil { // 还原为同一个覆盖段
+				segments[last].Block = &tags.Block{Tags: slices.Concat(segments[last].Block.Tags, seg.Block.Tags)}
+			} else {
+				segments = append(segments, seg)
+			}
+			if seg.Block == nil {
+				continue
+			}
+			if kt := seg.Block.Last("kt"); kt != nil {
+				cursor = start + tagCentiseconds(kt)
+			}
+		}
+	}
+	return tags.JoinText(segments)
+}
+
+// 将对话拆分为卡拉 OK 音节
+func (di *DialogueInfo) Syllables() ([]*Syllable, error) {
+	start, err := di.Start()
+	if err != nil {
+		return nil, err
+	}
+	return ParseKaraoke(di.Fields["Text"], start), nil
+}
+
+// 根据音节重新生成对话文本
+func (di *DialogueInfo) SetSyllables(syllables []*Syllable) error {
+	start, err := di.Start()
+	if err != nil {
+		return err
+	}
+	di.Fields["Text"] = JoinKaraoke(syllables, start)
+	return nil
+}
+
+// 以厘秒为单位的标签参数，无法解析或为负数时返回 0
+func tagCentiseconds(tag *tags.Tag) Timestamp {
+	v, err := strconv.ParseFloat(tag.Arg(), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return Timestamp(math.Round(v))
+}
@@ -0,0 +1,99 @@
+package ass_test
+
+import (
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKaraoke(t *testing.T) {
+	type syllable struct {
+		kind       string
+		start, end ass.Timestamp
+		text       string
+	}
+	testCases := []struct {
+		name   string
+		text   string
+		expect []syllable
+	}{
+		{
+			name: "普通卡拉OK",
+			text: `{\an8\fad(100,100)}{\k20}ka{\kf30\c&HFF0000&}ra{\ko15}o{\K10}ke`,
+			expect: []syllable{
+				{start: 1000, end: 1000},
+				{kind: "k", start: 1000, end: 1020, text: "ka"},
+				{kind: "kf", start: 1020, end: 1050, text: "ra"},
+				{kind: "ko", start: 1050, end: 1065, text: "o"},
+				{kind: "K", start: 1065, end: 1075, text: "ke"},
+			},
+		},
+		{
+			name: "同一覆盖段中的多个标签",
+			text: `{\be1\k10\k20}あ{\k5}い{\1c&H00FF00&}う`,
+			expect: []syllable{
+				{start: 1000, end: 1000},
+				{kind: "k", start: 1000, end: 1010},
+				{kind: "k", start: 1010, end: 1030, text: "あ"},
+				{kind: "k", start: 1030, end: 1035, text: "いう"},
+			},
+		},
+		{
+			name: "kt重置时间",
+			text: `{\k10}a{\kt50\k10}b`,
+			expect: []syllable{
+				{kind: "k", start: 1000, end: 1010, text: "a"},
+				{kind: "k", start: 1050, end: 1060, text: "b"},
+			},
+		},
+		{
+			name:   "没有卡拉OK标签",
+			text:   `{\b1}歌词`,
+			expect: []syllable{{start: 1000, end: 1000, text: "歌词"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			syllables := ass.ParseKaraoke(tc.text, 1000)
+			require.Len(t, syllables, len(tc.expect))
+			for i, syl := range syllables {
+				if tc.expect[i].kind == "" {
+					require.Nil(t, syl.Tag)
+				} else {
+					require.Equal(t, tc.expect[i].kind, syl.Tag.Name)
+				}
+				require.Equal(t, tc.expect[i].start, syl.Start)
+				require.Equal(t, tc.expect[i].end, syl.End)
+				require.Equal(t, tc.expect[i].text, syl.Text())
+			}
+
+			// 未修改时还原为原始文本
+			require.Equal(t, tc.text, ass.JoinKaraoke(syllables, 1000))
+		})
+	}
+}
+
+func TestEditKaraoke(t *testing.T) {
+	di := ass.NewDialogueInfo(map[string]string{
+		"Start": "0:00:10.00",
+		"End":   "0:00:12.00",
+		"Text":  `{\an8}{\k20}ka{\kf30\c&HFF0000&}ra{\k15}o`,
+	}, nil)
+
+	syllables, err := di.Syllables()
+	require.NoError(t, err)
+	require.Len(t, syllables, 4)
+
+	// 延长第二个音节，推迟第三个音节并追加新音节
+	syllables[2].End += 10
+	syllables[3].Start += 25
+	syllables[3].End += 25
+	syllables = append(syllables, ass.NewSyllable("kf", syllables[3].End, syllables[3].End+40, "ke"))
+	require.NoError(t, di.SetSyllables(syllables))
+	require.Equal(t, `{\an8}{\k20}ka{\kf40\c&HFF0000&}ra{\k15}{\k15}o{\kf40}ke`, di.Fields["Text"])
+
+	_, err = ass.NewDialogueInfo(map[string]string{"Start": "abc"}, nil).Syllables()
+	require.ErrorIs(t, err, ass.ErrInvalidTimestamp)
+}
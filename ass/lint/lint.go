@@ -0,0 +1,335 @@
+// Package lint 检查 ASS 字幕中的常见问题
+//
+// 检查结果包含行号与列号，可以输出为文本或 JSON，用于发布前的质量检查
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 检查规则
+type Rule string
+
+const (
+	RuleUndefinedStyle   Rule = "undefined-style"   // 事件或 \r 引用了未定义的样式
+	RuleUnusedStyle      Rule = "unused-style"      // 样式未被任何事件引用
+	RuleDuplicateStyle   Rule = "duplicate-style"   // 样式名重复
+	RuleUnbalancedBraces Rule = "unbalanced-braces" // 花括号不匹配
+	RuleInvalidTimestamp Rule = "invalid-timestamp" // 时间戳不合法
+	RuleEndBeforeStart   Rule = "end-before-start"  // 结束时间早于开始时间
+	RuleInvalidColor     Rule = "invalid-color"     // 颜色值不合法
+	RuleMissingFont      Rule = "missing-font"      // 字体不存在
+	RuleOutOfDuration    Rule = "out-of-duration"   // 事件超出视频时长
+	RuleMalformedRow     Rule = "malformed-row"     // 字段数与 Format 不一致
+	RuleInvalidBold      Rule = "invalid-bold"      // 字重值不合法
+	RuleInvalidItalic    Rule = "invalid-italic"    // 斜体值不合法
+	RuleInvalidEvent     Rule = "invalid-event"     // 事件无法处理
+	RuleInvalidInfo      Rule = "invalid-info"      // [Script Info] 中的值不合法
+)
+
+// 各规则的严重程度
+var ruleSeverities = map[Rule]ass.Severity{
+	RuleUndefinedStyle:   ass.SeverityError,
+	RuleUnusedStyle:      ass.SeverityInfo,
+	RuleDuplicateStyle:   ass.SeverityWarning,
+	RuleUnbalancedBraces: ass.SeverityWarning,
+	RuleInvalidTimestamp: ass.SeverityError,
+	RuleEndBeforeStart:   ass.SeverityWarning,
+	RuleInvalidColor:     ass.SeverityError,
+	RuleMissingFont:      ass.SeverityError,
+	RuleOutOfDuration:    ass.SeverityWarning,
+	RuleMalformedRow:     ass.SeverityError,
+	RuleInvalidBold:      ass.SeverityWarning,
+	RuleInvalidItalic:    ass.SeverityWarning,
+	RuleInvalidEvent:     ass.SeverityError,
+	RuleInvalidInfo:      ass.SeverityWarning,
+}
+
+// 解析诊断代码对应的规则
+// 未定义的样式由 RuleUndefinedStyle 检查，不重复报告
+var diagnosticRules = map[ass.DiagnosticCode]Rule{
+	ass.CodeMalformedRow:      RuleMalformedRow,
+	ass.CodeInvalidBold:       RuleInvalidBold,
+	ass.CodeInvalidItalic:     RuleInvalidItalic,
+	ass.CodeInvalidEvent:      RuleInvalidEvent,
+	ass.CodeInvalidScriptInfo: RuleInvalidInfo,
+}
+
+// 样式中的颜色字段
+var colorFields = []string{"PrimaryColour", "SecondaryColour", "OutlineColour", "TertiaryColour", "BackColour"}
+
+// 判断字体是否存在
+type FontChecker interface {
+	HasFont(name string) bool
+}
+
+// 检查出的问题
+type Issue struct {
+	Rule     Rule         `json:"rule"`     // 规则
+	Severity ass.Severity `json:"severity"` // 严重程度
+	LineNum  uint         `json:"line"`     // 行号，0 表示未知
+	Column   uint         `json:"column"`   // 列号（按字符计，从 1 开始），0 表示未知
+	Message  string       `json:"message"`  // 问题描述
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", i.LineNum, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// 检查报告
+type Report struct {
+	Issues []Issue `json:"issues"` // 按行号与列号排序的问题
+}
+
+// 是否存在达到 severity 级别的问题
+func (r *Report) Failed(severity ass.Severity) bool {
+	return slices.ContainsFunc(r.Issues, func(i Issue) bool {
+		return i.Severity >= severity
+	})
+}
+
+// 按行输出文本格式的报告
+func (r *Report) WriteText(w io.Writer) error {
+	for _, issue := range r.Issues {
+		if _, err := fmt.Fprintln(w, issue.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 输出 JSON 格式的报告
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type linter struct {
+	ap     *ass.ASSParser
+	c      *config
+	used   map[string]struct{} // 被引用的样式名
+	fonts  map[string]bool     // 已检查过的字体是否存在
+	issues []Issue
+}
+
+// 检查已调用过 Parse 的字幕
+func Lint(ap *ass.ASSParser, opts ...Option) *Report {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	l := &linter{
+		ap:     ap,
+		c:      c,
+		used:   make(map[string]struct{}),
+		fonts:  make(map[string]bool),
+		issues: make([]Issue, 0),
+	}
+	l.checkDiagnostics()
+	l.checkStyles()
+	for _, di := range ap.EventTable.Events() {
+		l.checkEvent(di)
+	}
+	l.checkUnusedStyles()
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].LineNum != l.issues[j].LineNum {
+			return l.issues[i].LineNum < l.issues[j].LineNum
+		}
+		return l.issues[i].Column < l.issues[j].Column
+	})
+	return &Report{Issues: l.issues}
+}
+
+// 记录一个问题，未启用的规则会被忽略
+func (l *linter) add(rule Rule, lineNum uint, column uint, format string, a ...any) {
+	if len(l.c.rules) > 0 && !slices.Contains(l.c.rules, rule) {
+		return
+	}
+	l.issues = append(l.issues, Issue{
+		Rule:     rule,
+		Severity: ruleSeverities[rule],
+		LineNum:  lineNum,
+		Column:   column,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+// 将解析时产生的诊断信息转换为问题
+func (l *linter) checkDiagnostics() {
+	for _, d := range l.ap.Diagnostics {
+		if rule, ok := diagnosticRules[d.Code]; ok {
+			l.add(rule, d.LineNum, d.Column, "%s", d.Message)
+		}
+	}
+}
+
+// 检查重复的样式名、颜色与字体
+func (l *linter) checkStyles() {
+	defined := make(map[string]uint) // 样式名->第一次定义的行号
+	for _, si := range l.ap.StyleTable.Styles() {
+		name := si.Name()
+		if lineNum, ok := defined[name]; ok {
+			l.add(RuleDuplicateStyle, si.LineNum(), si.FieldColumn("Name"), `duplicate style "%s", first defined at line %d`, name, lineNum)
+		} else {
+			defined[name] = si.LineNum()
+		}
+
+		for _, field := range colorFields {
+			raw, ok := si.Fields[field]
+			if !ok {
+				continue
+			}
+			if _, err := ass.ParseColor(raw); err != nil {
+				l.add(RuleInvalidColor, si.LineNum(), si.FieldColumn(field), `invalid color "%s" in %s of style "%s"`, raw, field, name)
+			}
+		}
+		l.checkFont(si.Fields["Fontname"], si.LineNum(), si.FieldColumn("Fontname"))
+	}
+}
+
+// 检查事件的时间、样式与文本
+// Comment 行不会被渲染，只检查格式问题
+func (l *linter) checkEvent(di *ass.DialogueInfo) {
+	lineNum := di.LineNum()
+	start, startErr := di.Start()
+	if startErr != nil {
+		l.add(RuleInvalidTimestamp, lineNum, di.FieldColumn("Start"), `invalid start time "%s"`, di.Fields["Start"])
+	}
+	end, endErr := di.End()
+	if endErr != nil {
+		l.add(RuleInvalidTimestamp, lineNum, di.FieldColumn("End"), `invalid end time "%s"`, di.Fields["End"])
+	}
+	if startErr == nil && endErr == nil {
+		if end < start {
+			l.add(RuleEndBeforeStart, lineNum, di.FieldColumn("End"), "end time %s is before start time %s", end, start)
+		}
+		if l.c.duration > 0 && !di.IsComment() {
+			videoEnd := ass.TimestampFromDuration(l.c.duration)
+			if start >= videoEnd {
+				l.add(RuleOutOfDuration, lineNum, di.FieldColumn("Start"), "event starts after the end of the video (%s)", videoEnd)
+			} else if end > videoEnd {
+				l.add(RuleOutOfDuration, lineNum, di.FieldColumn("End"), "event ends after the end of the video (%s)", videoEnd)
+			}
+		}
+	}
+
+	if !di.IsComment() {
+		style := di.Fields["Style"]
+		if style == "" {
+			style = "Default"
+		}
+		l.useStyle(style, lineNum, di.FieldColumn("Style"))
+	}
+
+	text := di.Fields["Text"]
+	textCol := di.FieldColumn("Text")
+	l.checkBraces(text, lineNum, textCol)
+	l.checkTags(text, di.IsComment(), lineNum, textCol)
+}
+
+// 记录样式被引用，样式未定义时记录问题
+func (l *linter) useStyle(name string, lineNum uint, column uint) {
+	l.used[name] = struct{}{}
+	if l.ap.StyleTable.GetFontDescByName(name) == nil {
+		l.add(RuleUndefinedStyle, lineNum, column, `style "%s" not found`, name)
+	}
+}
+
+// 检查未被引用的样式
+func (l *linter) checkUnusedStyles() {
+	for _, si := range l.ap.StyleTable.Styles() {
+		if _, ok := l.used[si.Name()]; !ok {
+			l.add(RuleUnusedStyle, si.LineNum(), si.FieldColumn("Name"), `style "%s" is never used`, si.Name())
+		}
+	}
+}
+
+// 检查字体是否存在，同一字体只查询一次
+func (l *linter) checkFont(fontName string, lineNum uint, column uint) {
+	fontName = strings.TrimPrefix(fontName, "@")
+	if l.c.fontChecker == nil || fontName == "" {
+		return
+	}
+	found, ok := l.fonts[fontName]
+	if !ok {
+		found = l.c.fontChecker.HasFont(fontName)
+		l.fonts[fontName] = found
+	}
+	if !found {
+		l.add(RuleMissingFont, lineNum, column, `font "%s" not found`, fontName)
+	}
+}
+
+// 检查花括号是否匹配，覆盖段之外转义的 \{ \} 视为普通文本
+func (l *linter) checkBraces(text string, lineNum uint, textCol uint) {
+	open := -1 // 当前覆盖段 '{' 的位置
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if open < 0 && i+1 < len(text) && (text[i+1] == '{' || text[i+1] == '}') {
+				i++
+			}
+		case '{':
+			if open >= 0 {
+				l.add(RuleUnbalancedBraces, lineNum, column(text, textCol, i), `nested "{" inside override block`)
+			} else {
+				open = i
+			}
+		case '}':
+			if open < 0 {
+				l.add(RuleUnbalancedBraces, lineNum, column(text, textCol, i), `unmatched "}"`)
+			}
+			open = -1
+		}
+	}
+	if open >= 0 {
+		l.add(RuleUnbalancedBraces, lineNum, column(text, textCol, open), `unclosed "{"`)
+	}
+}
+
+// 检查样式覆盖标签中的颜色、字体与样式引用
+func (l *linter) checkTags(text string, comment bool, lineNum uint, textCol uint) {
+	pos := 0 // 当前片段在文本中的字节偏移
+	for _, seg := range tags.ParseText(text) {
+		raw := seg.String()
+		if seg.Block != nil {
+			blockStart := pos + 1 // 跳过 '{'
+			seg.Block.Walk(func(tag *tags.Tag) bool {
+				col := column(text, textCol, blockStart+tag.Offset)
+				arg := tag.Arg()
+				switch {
+				case tag.Is("c", "1c", "2c", "3c", "4c"):
+					if _, err := ass.ParseColor(arg); arg != "" && err != nil {
+						l.add(RuleInvalidColor, lineNum, col, `invalid color "%s"`, arg)
+					}
+				case tag.Is("fn") && !comment:
+					l.checkFont(arg, lineNum, col)
+				case tag.Is("r") && !comment && arg != "":
+					l.useStyle(arg, lineNum, col)
+				}
+				return true
+			})
+		}
+		pos += len(raw)
+	}
+}
+
+// 文本中字节偏移对应的列号，textCol 为 0 时返回 0
+func column(text string, textCol uint, offset int) uint {
+	if textCol == 0 {
+		return 0
+	}
+	return textCol + uint(utf8.RuneCountInString(text[:offset]))
+}
@@ -0,0 +1,157 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/AkimioJR/assfonts-go/ass/lint"
+	"github.com/stretchr/testify/require"
+)
+
+const lintASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Title,宋体,24,&HZZ,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Title,宋体,24,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Unused,黑体,24,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Comment: 0,0:00:00.00,0:00:05.00,Unused,,0,0,0,,注释
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\fn不存在\rTitle}你好{\c&HGG&}
+Dialogue: 0,0:00:05.00,0:00:04.00,Missing,,0,0,0,,丢失}{\b1
+Dialogue: 0,0:00:aa.00,0:25:00.00,Default,,0,0,0,,\{转义\}
+`
+
+type fontChecker []string
+
+func (fc fontChecker) HasFont(name string) bool {
+	for _, f := range fc {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLint(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(lintASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	report := lint.Lint(ap,
+		lint.WithFontChecker(fontChecker{"楷体", "宋体", "黑体"}),
+		lint.WithDuration(24*time.Minute),
+	)
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	require.Equal(t, `7:20: error: invalid color "&HZZ" in PrimaryColour of style "Title" (invalid-color)
+8:8: warning: duplicate style "Title", first defined at line 7 (duplicate-style)
+9:8: info: style "Unused" is never used (unused-style)
+14:52: error: font "不存在" not found (missing-font)
+14:69: error: invalid color "&HGG&" (invalid-color)
+15:24: warning: end time 0:00:04.00 is before start time 0:00:05.00 (end-before-start)
+15:35: error: style "Missing" not found (undefined-style)
+15:53: warning: unmatched "}" (unbalanced-braces)
+15:54: warning: unclosed "{" (unbalanced-braces)
+16:13: error: invalid start time "0:00:aa.00" (invalid-timestamp)
+`, text.String())
+	require.True(t, report.Failed(ass.SeverityError))
+
+	// 视频时长与规则过滤
+	content := strings.Replace(lintASSContent, "0:00:aa.00", "0:24:30.00", 1)
+	ap, err = ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	report = lint.Lint(ap, lint.WithDuration(24*time.Minute), lint.WithRules(lint.RuleOutOfDuration, lint.RuleEndBeforeStart))
+	require.Len(t, report.Issues, 2)
+	require.Equal(t, lint.RuleOutOfDuration, report.Issues[1].Rule)
+	require.Equal(t, "event starts after the end of the video (0:24:00.00)", report.Issues[1].Message)
+	require.False(t, report.Failed(ass.SeverityError))
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded struct {
+		Issues []map[string]any `json:"issues"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, map[string]any{
+		"rule":     "end-before-start",
+		"severity": "warning",
+		"line":     float64(15),
+		"column":   float64(24),
+		"message":  "end time 0:00:04.00 is before start time 0:00:05.00",
+	}, decoded.Issues[0])
+}
+
+func TestLintDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		info   string
+		styles string
+		events string
+		want   string
+	}{
+		{
+			name:   "脚本信息值不合法",
+			info:   "PlayResX: abc\n",
+			events: "Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,你好\n",
+			want: `3:11: warning: invalid value "abc" for PlayResX: invalid script info value (invalid-info)
+`,
+		},
+		{
+			name:   "样式缺少字段",
+			styles: "Style: Broken,楷体\n",
+			events: "Dialogue: 0,0:00:00.00,0:00:05.00,Broken,,0,0,0,,你好\n",
+			want: `6:1: error: row has 2 fields, but Format defines 23 (malformed-row)
+`,
+		},
+		{
+			name:   "样式多出字段",
+			styles: "Style: Extra,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,abc,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1,1\n",
+			events: "Dialogue: 0,0:00:00.00,0:00:05.00,Extra,,0,0,0,,你好,世界\n",
+			want: `6:1: error: row has 24 fields, but Format defines 23 (malformed-row)
+6:64: warning: invalid bold value "abc" in style "Extra" (invalid-bold)
+`,
+		},
+		{
+			name:   "事件缺少字段",
+			events: "Dialogue: 0,0:00:00.00\n",
+			want: `10:0: error: invalid end time "" (invalid-timestamp)
+10:1: error: row has 2 fields, but Format defines 10 (malformed-row)
+`,
+		},
+		{
+			name:   "时间戳为浮点数特殊值",
+			events: "Dialogue: 0,0:00:inf,0:00:0x1p3,Default,,0,0,0,,你好\n",
+			want: `10:13: error: invalid start time "0:00:inf" (invalid-timestamp)
+10:22: error: invalid end time "0:00:0x1p3" (invalid-timestamp)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			styles := tt.styles
+			if styles == "" {
+				styles = "Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1\n"
+			}
+			content := "[Script Info]\nScriptType: v4.00+\n" + tt.info +
+				"\n[V4+ Styles]\nFormat: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n" + styles +
+				"\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" + tt.events
+			ap, err := ass.NewASSParser(strings.NewReader(content))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+
+			var text bytes.Buffer
+			require.NoError(t, lint.Lint(ap, lint.WithRules(lint.RuleMalformedRow, lint.RuleInvalidBold, lint.RuleInvalidInfo, lint.RuleInvalidTimestamp)).WriteText(&text))
+			require.Equal(t, tt.want, text.String())
+		})
+	}
+}
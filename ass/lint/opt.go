@@ -0,0 +1,32 @@
+package lint
+
+import "time"
+
+type Option func(*config)
+
+type config struct {
+	fontChecker FontChecker   // 检查字体是否存在，为 nil 时不检查
+	duration    time.Duration // 视频时长，为 0 时不检查
+	rules       []Rule        // 启用的规则，为空时启用全部规则
+}
+
+// 使用 checker 检查样式与 \fn 使用的字体是否存在
+func WithFontChecker(checker FontChecker) Option {
+	return func(c *config) {
+		c.fontChecker = checker
+	}
+}
+
+// 检查事件是否超出视频时长
+func WithDuration(duration time.Duration) Option {
+	return func(c *config) {
+		c.duration = duration
+	}
+}
+
+// 只启用指定的规则
+func WithRules(rules ...Rule) Option {
+	return func(c *config) {
+		c.rules = rules
+	}
+}
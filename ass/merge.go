@@ -0,0 +1,282 @@
+package ass
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 合并字幕时 [Script Info] 的处理策略
+type ScriptInfoMergePolicy uint8
+
+const (
+	ScriptInfoMergeKeep    ScriptInfoMergePolicy = iota // 保留原有的值，只补充原本未设置的项
+	ScriptInfoMergeReplace                              // 合并进来的字幕中已设置的项覆盖原有的值
+)
+
+// 合并字幕的结果报告
+type MergeReport struct {
+	Renamed          map[string]string // 因与原有样式重名而被重命名的样式，原名称->新名称
+	Shared           []string          // 与原有的同名样式完全相同，直接使用原有样式的样式
+	Events           int               // 合并的事件数量
+	Fonts            []string          // 合并的嵌入字体
+	Graphics         []string          // 合并的嵌入图片
+	FontConflicts    []string          // 与原有的同名字体内容不同而未合并的字体
+	GraphicConflicts []string          // 与原有的同名图片内容不同而未合并的图片
+	DroppedFields    []string          // other 的事件中有值、但原有事件格式中没有而未合并的字段
+	PlayResMismatch  bool              // 两者的脚本分辨率是否不同
+	Resampled        bool              // 是否按脚本分辨率缩放了合并的样式与事件
+}
+
+// 将 other 的内容合并到字幕中，两者都需要已调用过 Parse
+// 脚本信息按 WithScriptInfoMergePolicy 指定的策略合并，注释与脚本分辨率不会被合并
+// other 的样式追加到样式表末尾，与原有样式重名且内容不同时重命名为 "<名称>_<序号>"，
+// 并同步修改 other 事件的 Style 字段与 \r 标签；内容相同时直接使用原有样式
+// other 的事件按原有的事件格式追加到事件表末尾，原有格式中没有的字段不会合并并记录在报告中，
+// 两者的脚本分辨率不同时记录在报告中，
+// 使用 WithMergeResample 时按 Resample 的规则缩放 other 的样式与事件
+// [Fonts] 与 [Graphics] 中原本不存在的文件追加到对应区块，同名且内容不同的文件不合并并记录为冲突
+// other 不会被修改，合并后会重新统计 FontSets
+func (ap *ASSParser) Merge(other *ASSParser, opts ...MergeOption) (*MergeReport, error) {
+	if !ap.parsed || !other.parsed {
+		return nil, fmt.Errorf("failed to merge ass content: %w", ErrNotParsed)
+	}
+	c := &mergeConfig{infoPolicy: ScriptInfoMergeKeep}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	report := &MergeReport{Renamed: make(map[string]string), Shared: make([]string, 0)}
+	var st *resampleState
+	srcX, srcY := other.ScriptInfo.PlayRes()
+	dstX, dstY := ap.ScriptInfo.PlayRes()
+	if srcX != dstX || srcY != dstY {
+		report.PlayResMismatch = true
+		if c.resample {
+			st = newResampleState(srcX, srcY, dstX, dstY, c.aspect)
+			report.Resampled = true
+		}
+	}
+	styles, err := ap.mergeStyles(other, st, report)
+	if err != nil {
+		return nil, err
+	}
+	fonts, err := newEmbeddedLines(ap.fontsContents, other.fontsContents, "fontname:", &report.Fonts, &report.FontConflicts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge embedded fonts: %w", err)
+	}
+	graphics, err := newEmbeddedLines(ap.graphicsContents, other.graphicsContents, "filename:", &report.Graphics, &report.GraphicConflicts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge embedded graphics: %w", err)
+	}
+
+	// 检查完成后再修改，出错时字幕保持不变
+	ap.ScriptInfo.merge(other.ScriptInfo, c.infoPolicy)
+	for _, si := range styles {
+		ap.StyleTable.Append(si)
+	}
+	for _, di := range other.EventTable.rows {
+		event := NewDialogueInfo(maps.Clone(di.Fields), nil)
+		event.SetComment(di.comment)
+		renameStyleRefs(event, report.Renamed)
+		if st != nil {
+			st.resampleEvent(event)
+		}
+		ap.EventTable.Append(event)
+	}
+	report.Events = len(other.EventTable.rows)
+	report.DroppedFields = droppedEventFields(other.EventTable, ap.EventTable.format())
+	ap.fontsContents = append(ap.fontsContents, fonts...)
+	ap.graphicsContents = append(ap.graphicsContents, graphics...)
+	ap.CollectFontSets()
+	return report, nil
+}
+
+// 返回 et 的事件中有值、但 format 中没有的字段，按 et 的格式定义中的顺序排列
+func droppedEventFields(et *EventTable, format *FormatInfo) []string {
+	var dropped []string
+	for _, name := range et.format().Fields {
+		if slices.Contains(format.Fields, name) {
+			continue
+		}
+		if slices.ContainsFunc(et.rows, func(di *DialogueInfo) bool { return di.Fields[name] != "" }) {
+			dropped = append(dropped, name)
+		}
+	}
+	return dropped
+}
+
+// 按原有样式表的格式生成需要追加的样式，并记录重名样式的处理结果
+// st 不为 nil 时先缩放样式，再与原有样式比较
+func (ap *ASSParser) mergeStyles(other *ASSParser, st *resampleState, report *MergeReport) ([]*StyleInfo, error) {
+	newNames := make(map[string]struct{}) // 已分配的新名称
+	taken := func(name string) bool {
+		_, ok := newNames[name]
+		return ok || ap.StyleTable.Get(name) != nil || other.StyleTable.Get(name) != nil
+	}
+
+	styles := make([]*StyleInfo, 0, len(other.StyleTable.rows))
+	for _, si := range other.StyleTable.rows {
+		style, err := si.Style()
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge style \"%s\": %w", si.Name(), err)
+		}
+		if st != nil {
+			st.resampleStyle(style)
+		}
+		name := si.Name()
+		if newName, ok := report.Renamed[name]; ok {
+			style.Name = newName
+		} else if slices.Contains(report.Shared, name) {
+			continue
+		} else if origin := ap.StyleTable.Get(name); origin != nil {
+			originStyle, err := origin.Style()
+			if err == nil && sameStyle(originStyle, style) {
+				report.Shared = append(report.Shared, name)
+				continue
+			}
+			style.Name = uniqueStyleName(name, taken)
+			newNames[style.Name] = struct{}{}
+			report.Renamed[name] = style.Name
+		}
+		styles = append(styles, NewStyleInfo(style, ap.StyleTable.format()))
+	}
+	return styles, nil
+}
+
+// 返回不与已有样式重名的新名称
+func uniqueStyleName(name string, taken func(string) bool) string {
+	for i := 2; ; i++ {
+		newName := fmt.Sprintf("%s_%d", name, i)
+		if !taken(newName) {
+			return newName
+		}
+	}
+}
+
+// 判断两个样式除名称外是否完全相同
+func sameStyle(a *Style, b *Style) bool {
+	x, y := *a, *b
+	x.Name, y.Name = "", ""
+	return x.Line(DefaultStyleFormat) == y.Line(DefaultStyleFormat) && maps.Equal(x.Others, y.Others)
+}
+
+// 按 renamed 修改事件的 Style 字段与文本中 \r 标签引用的样式名
+// Style 字段为空时事件使用 Default 样式，Default 被重命名时同样修改
+func renameStyleRefs(di *DialogueInfo, renamed map[string]string) {
+	if len(renamed) == 0 {
+		return
+	}
+	if newName, ok := renamed[eventStyle(di)]; ok {
+		di.Fields["Style"] = newName
+	}
+
+	text, ok := di.Fields["Text"]
+	if !ok || !strings.Contains(text, `\r`) {
+		return
+	}
+	segments := tags.ParseText(text)
+	changed := false
+	for _, seg := range segments {
+		if seg.Block == nil {
+			continue
+		}
+		seg.Block.Walk(func(tag *tags.Tag) bool {
+			if newName, ok := renamed[tag.Arg()]; ok && tag.Is("r") {
+				tag.Args = []string{newName}
+				changed = true
+			}
+			return true
+		})
+	}
+	if changed {
+		di.Fields["Text"] = tags.JoinText(segments)
+	}
+}
+
+// 合并脚本信息
+// 脚本分辨率决定了原有事件的坐标与字号，保持不变
+func (si *ScriptInfo) merge(other *ScriptInfo, policy ScriptInfoMergePolicy) {
+	for _, key := range scriptInfoKeys {
+		value, ok := other.get(key)
+		if !ok || slices.Contains(resolutionKeys, key) {
+			continue
+		}
+		if _, exists := si.get(key); exists && policy == ScriptInfoMergeKeep {
+			continue
+		}
+		si.set(key, value) // 无法解析的值不会覆盖原有的值
+	}
+	for _, entry := range other.Others {
+		if entry.Key == "" {
+			continue // 注释不合并
+		}
+		idx := slices.IndexFunc(si.Others, func(e ScriptInfoEntry) bool {
+			return strings.EqualFold(e.Key, entry.Key)
+		})
+		switch {
+		case idx < 0:
+			si.Others = append(si.Others, ScriptInfoEntry{Key: entry.Key, Value: entry.Value})
+		case policy == ScriptInfoMergeReplace:
+			si.Others[idx].Value = entry.Value
+		}
+	}
+}
+
+// 合并脚本信息时保持不变的键
+var resolutionKeys = []string{"PlayResX", "PlayResY", "LayoutResX", "LayoutResY"}
+
+// 返回 src 中文件名不存在于 dst 的嵌入文件对应的内容，并将文件名追加到 names
+// 与 dst 中的同名文件内容相同时跳过，不同时将文件名追加到 conflicts
+// 有新文件时末尾追加一个空行
+func newEmbeddedLines(dst []ContentInfo, src []ContentInfo, keyword string, names *[]string, conflicts *[]string) ([]ContentInfo, error) {
+	dstBlocks, err := splitEmbeddedBlocks(dst, keyword)
+	if err != nil {
+		return nil, err
+	}
+	srcBlocks, err := splitEmbeddedBlocks(src, keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]ContentInfo, 0)
+	for _, block := range srcBlocks {
+		if idx := slices.IndexFunc(dstBlocks, func(b embeddedBlock) bool { return b.name == block.name }); idx >= 0 {
+			same, err := sameEmbeddedData(&dstBlocks[idx], &block)
+			if err != nil {
+				return nil, err
+			}
+			if !same && !slices.Contains(*conflicts, block.name) {
+				*conflicts = append(*conflicts, block.name)
+			}
+			continue
+		}
+		dstBlocks = append(dstBlocks, block)
+		*names = append(*names, block.name)
+		lines = append(lines, ContentInfo{RawContent: block.raw.RawContent})
+		for _, ci := range block.lines {
+			lines = append(lines, ContentInfo{RawContent: ci.RawContent})
+		}
+	}
+	if len(lines) > 0 {
+		lines = append(lines, ContentInfo{})
+	}
+	return lines, nil
+}
+
+// 判断两个嵌入文件解码后的数据是否相同
+func sameEmbeddedData(a *embeddedBlock, b *embeddedBlock) (bool, error) {
+	x, err := a.decode()
+	if err != nil {
+		return false, err
+	}
+	y, err := b.decode()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(x, y), nil
+}
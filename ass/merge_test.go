@@ -0,0 +1,203 @@
+package ass_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeBaseASSContent = `[Script Info]
+Title: 对白
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+
+[Fonts]
+fontname: a.ttf
+97&B
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,对白
+`
+
+const mergeOtherASSContent = `[Script Info]
+; 特效脚本
+Title: 特效
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+YCbCr Matrix: TV.709
+Custom: 1
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,宋体,50,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+
+[Fonts]
+fontname: a.ttf
+97&C
+fontname: b.ttf
+97&C
+
+[Graphics]
+filename: logo.png
+97&B
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 1,0:00:01.00,0:00:03.00,Default,,0,0,0,,合并{\rSign}特效{\t(0,100,\rDefault)}
+Comment: 1,0:00:01.00,0:00:03.00,,,0,0,0,,注释
+`
+
+func TestMerge(t *testing.T) {
+	base, err := ass.NewASSParser(strings.NewReader(mergeBaseASSContent))
+	require.NoError(t, err)
+	other, err := ass.NewASSParser(strings.NewReader(mergeOtherASSContent))
+	require.NoError(t, err)
+
+	_, err = base.Merge(other)
+	require.ErrorIs(t, err, ass.ErrNotParsed)
+
+	require.NoError(t, base.Parse())
+	require.NoError(t, other.Parse())
+	report, err := base.Merge(other)
+	require.NoError(t, err)
+	require.Equal(t, &ass.MergeReport{
+		Renamed:       map[string]string{"Default": "Default_2"},
+		Shared:        []string{"Sign"},
+		Events:        2,
+		Fonts:         []string{"b.ttf"},
+		Graphics:      []string{"logo.png"},
+		FontConflicts: []string{"a.ttf"},
+	}, report)
+
+	var b strings.Builder
+	require.NoError(t, base.Write(&b))
+	require.Equal(t, `[Script Info]
+Title: 对白
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+Custom: 1
+YCbCr Matrix: TV.709
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,60,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,40,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+Style: Default_2,宋体,50,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Fonts]
+fontname: a.ttf
+97&B
+
+fontname: b.ttf
+97&C
+
+[Graphics]
+filename: logo.png
+97&B
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,对白
+Dialogue: 1,0:00:01.00,0:00:03.00,Default_2,,0,0,0,,合并{\rSign}特效{\t(0,100,\rDefault_2)}
+Comment: 1,0:00:01.00,0:00:03.00,Default_2,,0,0,0,,注释
+`, b.String())
+
+	// 合并进来的事件使用重命名后的样式统计字符
+	require.Contains(t, base.FontSets, ass.FontDesc{FontName: "宋体", Bold: 400})
+
+	// 覆盖脚本信息，other 不会被修改
+	base, err = ass.NewASSParser(strings.NewReader(mergeBaseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, base.Parse())
+	_, err = base.Merge(other, ass.WithScriptInfoMergePolicy(ass.ScriptInfoMergeReplace))
+	require.NoError(t, err)
+	require.Equal(t, "特效", base.ScriptInfo.Title)
+	require.Equal(t, "Default", other.EventTable.Events()[0].Fields["Style"])
+
+	// 同名且内容相同的嵌入文件不视为冲突
+	same, err := ass.NewASSParser(strings.NewReader(mergeBaseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, same.Parse())
+	report, err = base.Merge(same)
+	require.NoError(t, err)
+	require.Empty(t, report.Fonts)
+	require.Empty(t, report.FontConflicts)
+}
+
+func TestMergePlayRes(t *testing.T) {
+	content := strings.Replace(mergeOtherASSContent, "PlayResX: 1920\nPlayResY: 1080", "PlayResX: 1280\nPlayResY: 720", 1)
+	content = strings.Replace(content, "合并{", `{\pos(640,360)}合并{`, 1)
+	other, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, other.Parse())
+
+	testCases := []struct {
+		name      string
+		opts      []ass.MergeOption
+		resampled bool
+		size      float64
+		text      string
+	}{
+		{name: "只报告分辨率不同", size: 50, text: `{\pos(640,360)}合并`},
+		{name: "缩放样式与事件", opts: []ass.MergeOption{ass.WithMergeResample(ass.AspectStretch)}, resampled: true, size: 75, text: `{\pos(960,540)}合并`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base, err := ass.NewASSParser(strings.NewReader(mergeBaseASSContent))
+			require.NoError(t, err)
+			require.NoError(t, base.Parse())
+
+			// 覆盖脚本信息时同样保留原有的分辨率
+			opts := append(tc.opts, ass.WithScriptInfoMergePolicy(ass.ScriptInfoMergeReplace))
+			report, err := base.Merge(other, opts...)
+			require.NoError(t, err)
+			require.True(t, report.PlayResMismatch)
+			require.Equal(t, tc.resampled, report.Resampled)
+			require.Equal(t, 1920, base.ScriptInfo.PlayResX)
+			require.Equal(t, 1080, base.ScriptInfo.PlayResY)
+
+			style, err := base.StyleTable.Get("Default_2").Style()
+			require.NoError(t, err)
+			require.Equal(t, tc.size, style.FontSize)
+			require.True(t, strings.HasPrefix(base.EventTable.Events()[1].Fields["Text"], tc.text))
+		})
+	}
+}
+
+func TestMergeEventFormat(t *testing.T) {
+	// other 的事件格式多出 Marked，缺少 Effect
+	content := strings.Replace(mergeOtherASSContent, "Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text", "Format: Marked, Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Text", 1)
+	content = strings.Replace(content, "Dialogue: 1,0:00:01.00,0:00:03.00,Default,,0,0,0,,", "Dialogue: Marked=1,1,0:00:01.00,0:00:03.00,Default,,0,0,0,", 1)
+	content = strings.Replace(content, "Comment: 1,0:00:01.00,0:00:03.00,,,0,0,0,,", "Comment: ,1,0:00:01.00,0:00:03.00,,,0,0,0,", 1)
+	other, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, other.Parse())
+	base, err := ass.NewASSParser(strings.NewReader(mergeBaseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, base.Parse())
+
+	report, err := base.Merge(other)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Marked"}, report.DroppedFields)
+	require.Equal(t, "0:00:01.00", base.EventTable.Events()[1].Fields["Start"])
+
+	// 格式相同时没有丢失的字段
+	same, err := ass.NewASSParser(strings.NewReader(mergeOtherASSContent))
+	require.NoError(t, err)
+	require.NoError(t, same.Parse())
+	report, err = base.Merge(same)
+	require.NoError(t, err)
+	require.Empty(t, report.DroppedFields)
+}
@@ -0,0 +1,185 @@
+package ass
+
+type ParserOption func(*parserConfig)
+
+type parserConfig struct {
+	withComments bool         // 统计字符时是否包括 Comment 行
+	strict       bool         // 是否将警告及以上级别的诊断信息视为错误
+	renderer     Renderer     // 统计字符时模拟的渲染器
+	encoding     TextEncoding // 输入的编码，默认自动检测
+	fallback     bool         // 样式未定义时是否按渲染器的行为回退到默认样式
+}
+
+// 统计字符时包括 Comment 行
+func WithComments() ParserOption {
+	return func(c *parserConfig) {
+		c.withComments = true
+	}
+}
+
+// 严格模式：存在警告及以上级别的诊断信息时 Parse 返回错误
+func WithStrict() ParserOption {
+	return func(c *parserConfig) {
+		c.strict = true
+	}
+}
+
+// 按指定渲染器的行为统计字符
+// 例如 \h 会被渲染为 U+00A0，\n 在 WrapStyle 不为 2 时会被渲染为空格
+func WithRenderer(renderer Renderer) ParserOption {
+	return func(c *parserConfig) {
+		c.renderer = renderer
+	}
+}
+
+// 按指定编码读取输入，不进行自动检测
+func WithEncoding(enc TextEncoding) ParserOption {
+	return func(c *parserConfig) {
+		c.encoding = enc
+	}
+}
+
+// 与 VSFilter、libass 一致，对话引用未定义的样式时回退到 Default 样式，
+// 不存在 Default 样式时使用内置的默认字体，并记录警告
+// \r 引用未定义的样式时重置为对话的初始样式
+func WithStyleFallback() ParserOption {
+	return func(c *parserConfig) {
+		c.fallback = true
+	}
+}
+
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	fontPolicy FontMergePolicy
+	report     *EmbedReport
+	encoding   TextEncoding
+}
+
+func newWriteConfig(opts []WriteOption) *writeConfig {
+	c := &writeConfig{
+		fontPolicy: FontMergeDrop,
+		report:     nil,
+		encoding:   EncodingUTF8,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// 设置原有嵌入字体的合并策略
+func WithFontMergePolicy(policy FontMergePolicy) WriteOption {
+	return func(c *writeConfig) {
+		c.fontPolicy = policy
+	}
+}
+
+// 写入完成后将嵌入结果报告保存到 report
+func WithEmbedReport(report *EmbedReport) WriteOption {
+	return func(c *writeConfig) {
+		c.report = report
+	}
+}
+
+// 设置输出的编码，默认为 UTF-8
+// EncodingAuto 表示使用读取时的原始编码
+func WithOutputEncoding(enc TextEncoding) WriteOption {
+	return func(c *writeConfig) {
+		c.encoding = enc
+	}
+}
+
+type ResampleOption func(*resampleConfig)
+
+type resampleConfig struct {
+	aspect AspectMode // 宽高比不同时的处理方式
+}
+
+// 设置源分辨率与目标分辨率宽高比不同时的处理方式，默认为 AspectStretch
+func WithAspectMode(mode AspectMode) ResampleOption {
+	return func(c *resampleConfig) {
+		c.aspect = mode
+	}
+}
+
+type MergeOption func(*mergeConfig)
+
+type mergeConfig struct {
+	infoPolicy ScriptInfoMergePolicy // [Script Info] 的合并策略
+	resample   bool                  // 脚本分辨率不同时是否缩放合并的样式与事件
+	aspect     AspectMode            // 缩放时宽高比不同的处理方式
+}
+
+// 设置 [Script Info] 的合并策略，默认为 ScriptInfoMergeKeep
+func WithScriptInfoMergePolicy(policy ScriptInfoMergePolicy) MergeOption {
+	return func(c *mergeConfig) {
+		c.infoPolicy = policy
+	}
+}
+
+// 两者的脚本分辨率不同时，按 mode 缩放合并进来的样式与事件
+func WithMergeResample(mode AspectMode) MergeOption {
+	return func(c *mergeConfig) {
+		c.resample = true
+		c.aspect = mode
+	}
+}
+
+type SplitOption func(*splitConfig)
+
+type splitConfig struct {
+	keepEmbedded bool // 是否保留原有的嵌入字体与图片
+}
+
+// 拆分时每个部分保留原有的 [Fonts] 与 [Graphics] 区块
+// 默认丢弃，原有的嵌入字体按整个字幕子集化，通常需要为每个部分重新嵌入
+func WithKeepEmbedded() SplitOption {
+	return func(c *splitConfig) {
+		c.keepEmbedded = true
+	}
+}
+
+type PruneOption func(*pruneConfig)
+
+type pruneConfig struct {
+	dedupe bool // 是否合并内容完全相同的样式
+}
+
+// 清理样式表时合并除名称外完全相同的样式，并修改事件中对被合并样式的引用
+func WithDeduplicate() PruneOption {
+	return func(c *pruneConfig) {
+		c.dedupe = true
+	}
+}
+
+type ImportOption func(*importConfig)
+
+type importConfig struct {
+	names    []string          // 需要导入的样式名，为空时导入全部样式
+	policy   StyleImportPolicy // 同名样式的处理策略
+	resample bool              // 脚本分辨率不同时是否缩放导入的样式
+	aspect   AspectMode        // 缩放时宽高比不同的处理方式
+}
+
+// 只导入指定名称的样式
+func WithImportStyles(names ...string) ImportOption {
+	return func(c *importConfig) {
+		c.names = append(c.names, names...)
+	}
+}
+
+// 设置同名样式的处理策略，默认为 StyleImportReplace
+func WithStyleImportPolicy(policy StyleImportPolicy) ImportOption {
+	return func(c *importConfig) {
+		c.policy = policy
+	}
+}
+
+// 模板与字幕的脚本分辨率不同时，按 mode 缩放导入的样式
+func WithImportResample(mode AspectMode) ImportOption {
+	return func(c *importConfig) {
+		c.resample = true
+		c.aspect = mode
+	}
+}
@@ -2,57 +2,184 @@ package ass
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
-	"unicode"
+	"unicode/utf8"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
 )
 
 type ASSParser struct {
-	Contents   []ContentInfo             // 元素内容
-	StyleTable *StyleTable               // 样式表
-	EventTable *EventTable               // 事件表
-	FontSets   map[FontDesc]CodepointSet // 字体集
+	Contents         []ContentInfo             // 元素内容
+	ScriptInfo       *ScriptInfo               // 脚本信息
+	StyleTable       *StyleTable               // 样式表
+	EventTable       *EventTable               // 事件表
+	FontSets         map[FontDesc]CodepointSet // 字体集
+	Diagnostics      []Diagnostic              // 解析过程中产生的诊断信息
+	fontsContents    []ContentInfo             // [Fonts] 区块内容
+	graphicsContents []ContentInfo             // [Graphics] 区块内容
+	config           parserConfig              // 解析配置
+	parsed           bool                      // 是否已调用 Parse 建立样式表与事件表
+	encoding         TextEncoding              // 原始内容的编码
+	bom              bool                      // 原始内容是否带有 BOM
+	lineEnding       string                    // 原始内容的换行符，"\n" 或 "\r\n"
+	noFinalEOL       bool                      // 原始内容的最后一行是否没有换行符
 }
 
-func NewASSParser(reader io.Reader) (*ASSParser, error) {
+func NewASSParser(reader io.Reader, opts ...ParserOption) (*ASSParser, error) {
 	ap := &ASSParser{
-		Contents:   make([]ContentInfo, 0, 200),
-		StyleTable: NewStyleTable(make(map[string]FontDesc)),
-		EventTable: &EventTable{rows: make([]*DialogueInfo, 0)},
-		FontSets:   make(map[FontDesc]CodepointSet),
+		Contents:    make([]ContentInfo, 0, 200),
+		ScriptInfo:  NewScriptInfo(),
+		StyleTable:  NewStyleTable(make(map[string]FontDesc)),
+		EventTable:  NewEventTable(),
+		FontSets:    make(map[FontDesc]CodepointSet),
+		Diagnostics: make([]Diagnostic, 0),
+	}
+	for _, opt := range opts {
+		opt(&ap.config)
+	}
+
+	decoded, enc, bom, err := decodeReader(reader, ap.config.encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new ASSParser: %w", err)
 	}
+	ap.encoding, ap.bom = enc, bom
 
 	var lineNum uint = 0
-	var inFontsSection = false
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
+	var es embeddedState
+	br := bufio.NewReader(decoded)
+	for {
+		ci, err := ap.readLine(br, lineNum+1) // 读取一行
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to new ASSParser: %w", err)
+		}
 		lineNum++
-		line := scanner.Text() // 读取一行
-		temp := strings.TrimSpace(strings.ToLower(line))
 
-		switch temp {
+		// 区块标题行保留在 Contents 中，写入时在原位置写入对应的区块
+		switch es.section(ci) {
 		case "[fonts]":
-			inFontsSection = true // 设置标志位
-			continue              // 跳过 [Fonts] 行
-		case "[events]", "[script info]", "[v4 styles]", "[v4+ styles]", "[graphics]":
-			inFontsSection = false // 清除标志位
+			ap.fontsContents = append(ap.fontsContents, ci)
+		case "[graphics]":
+			ap.graphicsContents = append(ap.graphicsContents, ci)
+		default:
+			ap.Contents = append(ap.Contents, ci)
 		}
-		if !inFontsSection {
-			ap.Contents = append(ap.Contents, ContentInfo{LineNum: lineNum, RawContent: line})
+	}
+	return ap, nil
+}
+
+// 当前是否位于 [Fonts] 或 [Graphics] 区块中
+type embeddedState struct {
+	inFonts    bool
+	inGraphics bool
+}
+
+// 根据一行内容更新状态，返回该行所属的嵌入区块的标题（"[fonts]" 或 "[graphics]"）
+// 区块标题行与其他区块中的行返回空字符串
+func (es *embeddedState) section(ci ContentInfo) string {
+	es.inFonts = inEmbeddedSection(ci.RawContent, "[fonts]", es.inFonts)
+	es.inGraphics = inEmbeddedSection(ci.RawContent, "[graphics]", es.inGraphics)
+	switch {
+	case es.inFonts && !isHeader(ci, "[fonts]"):
+		return "[fonts]"
+	case es.inGraphics && !isHeader(ci, "[graphics]"):
+		return "[graphics]"
+	}
+	return ""
+}
+
+// 根据区块标题判断之后的内容是否位于 title 指定的嵌入区块（[fonts] 或 [graphics]）中
+// in 为当前行之前是否位于该区块中，任何区块标题（包括 [Aegisub Extradata] 等未知区块）都会结束嵌入区块
+func inEmbeddedSection(line string, title string, in bool) bool {
+	header := strings.TrimSpace(line)
+	switch lower := strings.ToLower(header); {
+	case lower == title:
+		return true
+	case slices.Contains(knownSections, lower), isSectionHeader(header):
+		return false
+	}
+	return in
+}
+
+// 已知的区块标题，不区分大小写，全大写的标题无法通过 isSectionHeader 识别
+var knownSections = []string{
+	"[script info]", "[v4 styles]", "[v4+ styles]", "[v4+styles]", "[events]", "[fonts]", "[graphics]",
+	"[aegisub project garbage]", "[aegisub extradata]",
+}
+
+// 判断是否为 title 指定的区块标题行，不区分大小写
+func isHeader(ci ContentInfo, title string) bool {
+	return strings.ToLower(strings.TrimSpace(ci.RawContent)) == title
+}
+
+// 判断是否为未知的区块标题
+// UUEncode 数据行只包含 '!' 到 '`' 之间的字符，可能以 '[' 开头并以 ']' 结尾，
+// 因此只把包含小写字母或空格等数据行中不会出现的字符的行视为区块标题
+func isSectionHeader(line string) bool {
+	if len(line) < 3 || line[0] != '[' || line[len(line)-1] != ']' {
+		return false
+	}
+	return strings.ContainsFunc(line, func(r rune) bool { return r < '!' || r > '`' })
+}
+
+// 读取一行并去除换行符，没有更多内容时返回 io.EOF
+func (ap *ASSParser) readLine(br *bufio.Reader, lineNum uint) (ContentInfo, error) {
+	line, err := br.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return ContentInfo{}, err
+	}
+	line, eol := ap.trimLine(line, lineNum)
+	return ContentInfo{LineNum: lineNum, RawContent: line, eol: eol}, nil
+}
+
+// 去除行尾的换行符与首行的 BOM，并记录下来用于写入时还原
+// 返回去除后的内容与该行的换行符
+func (ap *ASSParser) trimLine(line string, lineNum uint) (string, string) {
+	if lineNum == 1 {
+		if trimmed, ok := strings.CutPrefix(line, "\uFEFF"); ok {
+			line, ap.bom = trimmed, true
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to new ASSParser: %w", err)
+	line, hasEOL := strings.CutSuffix(line, "\n")
+	ap.noFinalEOL = !hasEOL
+	if !hasEOL {
+		return line, ""
 	}
-	return ap, nil
+	eol := "\n"
+	if trimmed, ok := strings.CutSuffix(line, "\r"); ok {
+		line, eol = trimmed, "\r\n"
+	}
+	if ap.lineEnding == "" {
+		ap.lineEnding = eol // 新增的行使用第一个换行符
+	}
+	return line, eol
+}
+
+// 返回读取时使用的编码
+func (ap *ASSParser) Encoding() TextEncoding {
+	return ap.encoding
 }
 
+// 解析样式与事件，并统计所有事件用到的字符
+// 每次调用都会根据原始内容重新建立脚本信息、样式表与事件表，之前的修改会被丢弃
+// 单条事件处理失败时不会中断解析，而是记录到 Diagnostics 中
+// 严格模式下存在警告及以上级别的诊断信息时返回错误
 func (ap *ASSParser) Parse() error {
 	var s parseState
 	var err error
 
+	ap.Diagnostics = ap.Diagnostics[:0]
+	ap.ScriptInfo = NewScriptInfo()
+	ap.StyleTable.rows = ap.StyleTable.rows[:0]
+	ap.EventTable.rows = ap.EventTable.rows[:0]
+
 	for i := range ap.Contents {
 		s, err = ap.parseContent(i, s)
 		if err != nil {
@@ -67,32 +194,103 @@ func (ap *ASSParser) Parse() error {
 	if !s.hasEvent {
 		return ErrEventParseFailed
 	}
-	ap.cleanFontSets()
+	ap.parsed = true
+	ap.CollectFontSets()
+	return ap.checkStrict()
+}
+
+// 严格模式下存在警告及以上级别的诊断信息时返回错误
+func (ap *ASSParser) checkStrict() error {
+	if ap.config.strict {
+		if d, ok := ap.firstDiagnostic(SeverityWarning); ok {
+			return fmt.Errorf("failed to parse ass content in strict mode: %w", d)
+		}
+	}
 	return nil
 }
 
+// 重新统计所有事件用到的字符，修改样式表或事件表后可调用以更新 FontSets
+// 处理失败的事件会记录到 Diagnostics 中
+func (ap *ASSParser) CollectFontSets() {
+	ap.FontSets = make(map[FontDesc]CodepointSet)
+	for _, di := range ap.EventTable.rows {
+		ap.collectEvent(di)
+	}
+	ap.cleanFontSets()
+}
+
+// 统计单条事件用到的字符，失败时记录到 Diagnostics 中
+func (ap *ASSParser) collectEvent(di *DialogueInfo) {
+	if di.IsComment() && !ap.config.withComments {
+		return // 默认跳过 Comment 行
+	}
+	if err := ap.ParseDialogue(di); err != nil {
+		var d Diagnostic
+		if errors.As(err, &d) {
+			ap.Diagnostics = append(ap.Diagnostics, d)
+		} else {
+			ap.addDiagnostic(di.content, 0, SeverityError, CodeInvalidEvent, "%s", err)
+		}
+	}
+}
+
 func (ap *ASSParser) parseContent(i int, s parseState) (parseState, error) {
-	ci := ap.Contents[i]
+	item := Item{Content: &ap.Contents[i]}
+	s, err := ap.parseItem(&item, s)
+	if err != nil {
+		return s, err
+	}
+	if item.Event != nil {
+		ap.EventTable.rows = append(ap.EventTable.rows, item.Event)
+	}
+	return s, nil
+}
+
+// 解析一行内容，并根据内容设置 item 的类型
+// 样式会加入样式表，事件只保存在 item 中
+func (ap *ASSParser) parseItem(item *Item, s parseState) (parseState, error) {
+	ci := item.Content
+	item.Kind = ItemLine
 	// 检查区块开始
 	switch {
+	case startWith(ci.RawContent, "[Script Info]"):
+		s.inScriptInfoSection = true
+		s.inStyleSection = false
+		s.inEventSection = false
+		item.Kind = ItemSection
+		return s, nil
+
 	case startWith(ci.RawContent, "[V4+ Styles]"), startWith(ci.RawContent, "[V4 Styles]"):
 		s.inStyleSection = true
 		s.inEventSection = false
+		s.inScriptInfoSection = false
 		ap.StyleTable.Format = nil // 重置格式定义
+		item.Kind = ItemSection
 		return s, nil
 
 	case startWith(ci.RawContent, "[Events]"):
 		s.inEventSection = true
 		s.inStyleSection = false
+		s.inScriptInfoSection = false
 		ap.EventTable.Format = nil // 重置格式定义
+		item.Kind = ItemSection
 		return s, nil
 	case startWith(ci.RawContent, "["):
+		s.inScriptInfoSection = false
 		s.inStyleSection = false
 		s.inEventSection = false
+		item.Kind = ItemSection
+		return s, nil
 	}
 
 	// 根据当前状态处理行
 	switch {
+	case s.inScriptInfoSection:
+		if err := ap.ScriptInfo.parseLine(ci.RawContent); err != nil {
+			ap.addDiagnostic(ci, scriptInfoValueColumn(ci.RawContent), SeverityWarning, CodeInvalidScriptInfo, "%s", err)
+		}
+		item.Kind = ItemScriptInfo
+
 	case s.inStyleSection && startWith(ci.RawContent, "Format:"):
 		// 解析样式格式定义
 		format, err := ParseFormat(ci.RawContent)
@@ -100,17 +298,21 @@ func (ap *ASSParser) parseContent(i int, s parseState) (parseState, error) {
 			return s, err
 		}
 		ap.StyleTable.Format = format
+		item.Kind = ItemFormat
 
 	case s.inStyleSection && startWith(ci.RawContent, "Style:"):
 		if ap.StyleTable.Format == nil {
 			return s, ErrMissingFormat
 		}
-		si, err := parseStyleLine(&ap.Contents[i], ap.StyleTable.Format)
+		si, err := parseStyleLine(ci, ap.StyleTable.Format)
 		if err != nil {
 			return s, err
 		}
+		ap.checkFieldCount(ci, ap.StyleTable.Format)
+		ap.checkStyle(si)
 		ap.StyleTable.Append(si)
 		s.hasStyle = true
+		item.Kind, item.Style = ItemStyle, si
 
 	case s.inEventSection && startWith(ci.RawContent, "Format:"):
 		// 解析事件格式定义
@@ -119,26 +321,56 @@ func (ap *ASSParser) parseContent(i int, s parseState) (parseState, error) {
 			return s, err
 		}
 		ap.EventTable.Format = format
+		item.Kind = ItemFormat
 
 	case s.inEventSection && (startWith(ci.RawContent, "Dialogue:") || startWith(ci.RawContent, "Comment:")):
 		if ap.EventTable.Format == nil {
 			return s, ErrMissingFormat
 		}
-		di, err := parseEventLine(&ap.Contents[i], ap.EventTable.Format)
+		di, err := parseEventLine(ci, ap.EventTable.Format)
 		if err != nil {
 			return s, err
 		}
-		ap.EventTable.rows = append(ap.EventTable.rows, di)
+		ap.checkFieldCount(ci, ap.EventTable.Format)
 		s.hasEvent = true
+		item.Kind, item.Event = ItemEvent, di
 	}
 	return s, nil
 }
 
+// 检查数据行的字段数是否与格式定义一致
+// 最后一个字段为 Text 时允许其中包含逗号
+func (ap *ASSParser) checkFieldCount(ci *ContentInfo, format *FormatInfo) {
+	_, values, ok := strings.Cut(ci.RawContent, ":")
+	if !ok {
+		return
+	}
+	count := strings.Count(values, ",") + 1
+	want := len(format.Fields)
+	if count < want || (count > want && format.Fields[want-1] != "Text") {
+		ap.addDiagnostic(ci, 1, SeverityWarning, CodeMalformedRow, "row has %d fields, but Format defines %d", count, want)
+	}
+}
+
+// 检查样式中的字重与斜体值
+func (ap *ASSParser) checkStyle(si *StyleInfo) {
+	if boldStr := si.Fields["Bold"]; boldStr != "" {
+		if _, err := calculateBold(boldStr); err != nil {
+			ap.addDiagnostic(si.content, fieldColumn(si.content.RawContent, si.formatInfo, "Bold"), SeverityWarning, CodeInvalidBold, `invalid bold value "%s" in style "%s"`, boldStr, si.Fields["Name"])
+		}
+	}
+	if italicStr := si.Fields["Italic"]; italicStr != "" {
+		if _, err := calculateItalic(italicStr); err != nil {
+			ap.addDiagnostic(si.content, fieldColumn(si.content.RawContent, si.formatInfo, "Italic"), SeverityWarning, CodeInvalidItalic, `invalid italic value "%s" in style "%s"`, italicStr, si.Fields["Name"])
+		}
+	}
+}
+
 // 统计每种字体样式实际用到的字符集合
 func (ap *ASSParser) ParseDialogue(dialogue *DialogueInfo) error {
 	initialFD, err := ap.getFontDescStyle(dialogue)
 	if err != nil {
-		return fmt.Errorf("failed to get font description style for dialogue at line %d: %w", dialogue.content.LineNum, err)
+		return fmt.Errorf("failed to get font description style for dialogue: %w", err)
 	}
 
 	// 初始化字体集合
@@ -153,11 +385,18 @@ func (ap *ASSParser) ParseDialogue(dialogue *DialogueInfo) error {
 	}
 
 	runes := []rune(text)
-	currentFD := initialFD // 当前对话使用的字体描述
+	st := collectState{fd: initialFD} // 当前对话的状态
+	if ap.ScriptInfo != nil {
+		st.wrapStyle = ap.ScriptInfo.WrapStyle
+	}
+	var textCol uint // Text 字段的起始列号
+	if dialogue.content != nil {
+		textCol = fieldColumn(dialogue.content.RawContent, dialogue.formatInfo, "Text")
+	}
 
 	idx := 0
 	for idx < len(runes) {
-		idx = ap.gatherCharacter(runes, idx, &currentFD, &initialFD, dialogue.content)
+		idx = ap.gatherCharacter(runes, idx, &st, &initialFD, dialogue.content, textCol)
 	}
 	return nil
 }
@@ -186,27 +425,84 @@ func (ap *ASSParser) getFontDescStyle(dialogue *DialogueInfo) (FontDesc, error)
 	}
 
 	fd := ap.StyleTable.GetFontDescByName(styleName)
-	if fd == nil {
-		return FontDesc{}, fmt.Errorf("style '%s' not found", styleName)
+	if fd != nil {
+		return *fd, nil
+	}
+
+	d := Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeUnknownStyle,
+		Message:  fmt.Sprintf(`style "%s" not found`, styleName),
+	}
+	if dialogue.content != nil {
+		d.LineNum = dialogue.content.LineNum
+		d.Column = fieldColumn(dialogue.content.RawContent, dialogue.formatInfo, "Style")
+	}
+	if !ap.config.fallback {
+		return FontDesc{}, d
+	}
+
+	// 模拟渲染器的回退行为
+	fallback, fallbackName := ap.fallbackFontDesc()
+	d.Severity = SeverityWarning
+	d.Message = fmt.Sprintf(`style "%s" not found, fall back to %s`, styleName, fallbackName)
+	ap.Diagnostics = append(ap.Diagnostics, d)
+	return fallback, nil
+}
+
+// 返回未定义样式回退后使用的字体描述，以及用于诊断信息的描述
+// 优先使用 Default 样式，不存在时使用渲染器内置的默认样式
+func (ap *ASSParser) fallbackFontDesc() (FontDesc, string) {
+	if fd := ap.StyleTable.GetFontDescByName(defaultFontName); fd != nil {
+		return *fd, fmt.Sprintf(`style "%s"`, defaultFontName)
+	}
+	fd := FontDesc{
+		FontName: builtinFontName,
+		Bold:     defaultFontSize,
+		Italic:   defaultItalic,
 	}
-	return *fd, nil
+	return fd, fmt.Sprintf(`built-in font "%s"`, builtinFontName)
+}
+
+// 记录字体用到的字符
+func (ap *ASSParser) addCodepoint(fd *FontDesc, r rune) {
+	if fd.FontName == "" {
+		return
+	}
+	if _, ok := ap.FontSets[*fd]; !ok {
+		ap.FontSets[*fd] = make(CodepointSet)
+	}
+	ap.FontSets[*fd][r] = struct{}{}
 }
 
 // 处理对话文本中的每个字符，收集字体用到的字符
 // 返回下一个未处理字符的索引
-// fd 是当前对话使用的字体描述（不会进行修改）
-func (ap *ASSParser) gatherCharacter(runes []rune, idx int, currentFD *FontDesc, initialFD *FontDesc, ci *ContentInfo) int {
+// st 是当前对话的状态，initialFD 是对话样式对应的字体描述（不会进行修改）
+// textCol 为对话文本在原始行中的起始列号，用于记录诊断信息，0 表示未知
+func (ap *ASSParser) gatherCharacter(runes []rune, idx int, st *collectState, initialFD *FontDesc, ci *ContentInfo, textCol uint) int {
+	// 绘图模式下的内容是绘图指令，不是需要渲染的文字
+	// Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\p1}m 0 0 l 100 0 100 100 0 100{\p0}
+	if st.drawing && runes[idx] != '{' {
+		return idx + 1
+	}
+
+	profile := ap.config.renderer.profile()
 	if idx < len(runes)-1 && runes[idx] == '\\' {
 		switch runes[idx+1] {
-		case 'h', 'n', 'N': // 跳过 \h \n \N
+		case 'h': // 硬空格，渲染器会绘制为 U+00A0
+			if profile.hardSpace {
+				ap.addCodepoint(&st.fd, '\u00A0')
+			}
 			return idx + 2
-		case '{', '}': // 转译 \{ \}
-			if currentFD.FontName != "" {
-				if _, ok := ap.FontSets[*currentFD]; !ok {
-					ap.FontSets[*currentFD] = make(CodepointSet)
-				}
-				ap.FontSets[*currentFD][runes[idx+1]] = struct{}{}
+		case 'n': // 软换行，除 WrapStyle 2 外渲染器会绘制为空格
+			if profile.softBreak && st.wrapStyle != 2 {
+				ap.addCodepoint(&st.fd, ' ')
 			}
+			return idx + 2
+		case 'N': // 硬换行
+			return idx + 2
+		case '{', '}': // 转译 \{ \}
+			ap.addCodepoint(&st.fd, runes[idx+1])
 			return idx + 2 // 跳过 \{ \}
 		}
 	}
@@ -219,165 +515,329 @@ func (ap *ASSParser) gatherCharacter(runes []rune, idx int, currentFD *FontDesc,
 			endIdx++
 		}
 		if endIdx >= len(runes) { // 没有找到 '}'，直接加入当前字符
-			if currentFD.FontName != "" {
-				if _, ok := ap.FontSets[*currentFD]; !ok {
-					ap.FontSets[*currentFD] = make(CodepointSet)
-				}
-				ap.FontSets[*currentFD][runes[idx]] = struct{}{}
+			if !st.drawing {
+				ap.addCodepoint(&st.fd, runes[idx])
 			}
 			return idx + 1
 		} else { // 处理样式覆盖
 			// \fad(500,0)\fnB3CJROEU\fs22\frz19.65\c&H6C6D6F&\pos(468,349)
-			ap.StyleOverride(runes[idx+1:endIdx], currentFD, initialFD, ci)
+			var col uint
+			if textCol > 0 {
+				col = textCol + uint(idx) + 1
+			}
+			ap.styleOverride(runes[idx+1:endIdx], st, initialFD, ci, col)
 			return endIdx + 1
 		}
 	}
 	// 普通字符
-	if currentFD.FontName != "" {
-		if _, ok := ap.FontSets[*currentFD]; !ok {
-			ap.FontSets[*currentFD] = make(CodepointSet)
-		}
-		ap.FontSets[*currentFD][runes[idx]] = struct{}{}
+	if runes[idx] == '\t' && profile.tabAsSpace { // libass 将制表符绘制为空格
+		ap.addCodepoint(&st.fd, ' ')
+		return idx + 1
 	}
+	ap.addCodepoint(&st.fd, runes[idx])
 	return idx + 1
 }
 
+// 处理样式覆盖段中的标签，更新当前字体描述
 func (ap *ASSParser) StyleOverride(code []rune, currentFD *FontDesc, initialFD *FontDesc, ci *ContentInfo) {
-	currentFDCopy := *currentFD // 创建当前字体描述的副本
+	st := collectState{fd: *currentFD}
+	ap.styleOverride(code, &st, initialFD, ci, 0)
+	*currentFD = st.fd
+}
 
-	pos := 0
-	for pos < len(code) {
-		// 查找下一个标签开始位置
-		if code[pos] != '\\' {
-			pos++
-			continue
-		}
+// col 为 code 在原始行中的起始列号，0 表示未知
+func (ap *ASSParser) styleOverride(code []rune, st *collectState, initialFD *FontDesc, ci *ContentInfo, col uint) {
+	currentFDCopy := st.fd // 创建当前字体描述的副本
 
-		pos++                 // 跳过 '\'
-		if pos >= len(code) { // 如果已经到达字符串末尾，退出循环
-			break
+	raw := string(code)
+	// 只根据顶层标签更新状态，\t 中内嵌的标签不改变字体、换行方式与绘图模式
+	for _, tag := range tags.Parse(raw).Tags {
+		if tag.Kind != tags.KindTag {
+			continue // 跳过文本与未知标签
 		}
-		tagChar := code[pos] // 获取标签的第一个字符
-		pos++                // 移动到标签内容开始位置
 
-		switch tagChar {
-		case 'f': // 处理字体相关标签 (\fn, \fr, 等)
-			switch code[pos] {
-			case 'n': // \fn
-				pos++ // 跳过 'n'
-				if pos >= len(code) {
-					break // 如果已经到达字符串末尾，退出循环
-				}
+		var tagCol uint // 当前标签的列号
+		if col > 0 {
+			tagCol = col + uint(utf8.RuneCountInString(raw[:tag.Offset]))
+		}
 
-				var fontName string
-				fontName, pos = findTag(code, pos)
-				fontName = strings.TrimPrefix(strings.TrimSpace(fontName), "@")
-				if fontName != "" {
-					currentFDCopy.FontName = fontName
-				}
+		switch tag.Name {
+		case "fn": // 字体名称
+			fontName, vertical := splitVerticalFontName(tag.Arg())
+			if fontName != "" {
+				currentFDCopy.FontName = fontName
+				currentFDCopy.Vertical = vertical
 			}
 
-		case 'b': // 处理粗体标签 (\b)
-			if pos < len(code) && (unicode.IsDigit(rune(code[pos])) || code[pos] == '-' || code[pos] == ' ') {
-				var boldStr string
-				boldStr, pos = findTag(code, pos)
-				boldStr = strings.TrimSpace(boldStr)
-				if bold, err := calculateBold(boldStr); err == nil || err == ErrInvalidBoldValue {
-					currentFDCopy.Bold = bold
-				}
+		case "b": // 粗体
+			boldStr := tag.Arg()
+			if boldStr == "" {
+				break
 			}
-
-		case 'i': // 处理斜体标签 (\i)
-			if pos < len(code) && (unicode.IsDigit(rune(code[pos])) || code[pos] == '-' || code[pos] == ' ') {
-				var italicStr string
-				italicStr, pos = findTag(code, pos)
-				italicStr = strings.TrimSpace(italicStr)
-				if italic, err := calculateItalic(italicStr); err == nil || err == ErrInvalidItalicValue {
-					currentFDCopy.Italic = italic
-				}
+			bold, err := calculateBold(boldStr)
+			if err == nil || err == ErrInvalidBoldValue {
+				currentFDCopy.Bold = bold
+			}
+			if err != nil {
+				ap.addDiagnostic(ci, tagCol, SeverityWarning, CodeInvalidBold, `invalid bold value "%s"`, boldStr)
 			}
 
-		case 'r': // 处理样式重置标签 (\r)
-			// 检查是否是\rnd标签
-			if pos < len(code) && pos+1 < len(code) && code[pos] == 'n' && code[pos+1] == 'd' {
-				pos += 2 // 跳过 "nd"
-				continue
+		case "i": // 斜体
+			italicStr := tag.Arg()
+			if italicStr == "" {
+				break
+			}
+			italic, err := calculateItalic(italicStr)
+			if err == nil || err == ErrInvalidItalicValue {
+				currentFDCopy.Italic = italic
+			}
+			if err != nil {
+				ap.addDiagnostic(ci, tagCol, SeverityWarning, CodeInvalidItalic, `invalid italic value "%s"`, italicStr)
 			}
 
-			var styleName string
-			if pos < len(code) {
-				styleName, pos = findTag(code, pos)
+		case "fe": // 字符集，无参数时恢复为样式的字符集
+			charsetStr := tag.Arg()
+			if charsetStr == "" {
+				currentFDCopy.Charset = initialFD.Charset
+				break
+			}
+			if charset, err := calculateCharset(charsetStr); err == nil {
+				currentFDCopy.Charset = charset
 			}
-			styleName = strings.TrimSpace(styleName)
 
+		case "r": // 样式重置
+			styleName := tag.Arg()
 			if styleName == "" { // 无样式名时重置为初始样式
 				currentFDCopy = *initialFD
 			} else if desc := ap.StyleTable.GetFontDescByName(styleName); desc != nil { // 找到指定样式，更新当前字体描述
 				currentFDCopy = *desc
+			} else if ap.config.fallback { // 渲染器会重置为初始样式
+				currentFDCopy = *initialFD
+				ap.addDiagnostic(ci, tagCol, SeverityWarning, CodeUnknownStyle, `style "%s" not found, reset to the initial style`, styleName)
 			} else {
-				fmt.Printf("Style \"%s\" not found. (Line %d)\n", styleName, ci.LineNum)
+				ap.addDiagnostic(ci, tagCol, SeverityWarning, CodeUnknownStyle, `style "%s" not found`, styleName)
+			}
+
+		case "q": // 换行方式
+			if wrapStyle, err := strconv.Atoi(tag.Arg()); err == nil {
+				st.wrapStyle = wrapStyle
+			}
+
+		case "p": // 绘图模式，\p0 退出绘图模式
+			if scale, err := strconv.Atoi(tag.Arg()); err == nil {
+				st.drawing = scale > 0
 			}
 		}
 	}
-	*currentFD = currentFDCopy // 更新最终的字体描述
+	st.fd = currentFDCopy // 更新最终的字体描述
 }
 
-func (ap *ASSParser) WriteWithEmbeddedFonts(fontDatas map[string][]byte, writer io.Writer) error {
-	insertedFonts := false
-	var err error
+// 将字幕内容写入 writer，并写入嵌入字体的 [Fonts] 区块
+// [Fonts] 区块写入原有区块的位置，原本没有或位于 [Events] 之后时在 [Events] 之前插入
+// [Graphics] 区块保持原有的位置，原本没有时在 [Events] 之前插入
+// 原有的嵌入字体按 WithFontMergePolicy 指定的策略处理，默认全部丢弃
+// 调用过 Parse 时按脚本信息、样式表与事件表重新生成对应的内容，未修改的行保留原始文本
+func (ap *ASSParser) WriteWithEmbeddedFonts(fontDatas map[string][]byte, writer io.Writer, opts ...WriteOption) error {
+	c := newWriteConfig(opts)
+
+	enc := c.encoding
+	if enc == EncodingAuto {
+		enc = ap.encoding
+	}
+	hasGraphics := slices.ContainsFunc(ap.Contents, func(ci ContentInfo) bool { return isHeader(ci, "[graphics]") })
+	err := ap.write(writer, enc, ap.contentsReader(), hasGraphics, func(lw *lineWriter, header ContentInfo) error {
+		return ap.writeFonts(lw, header, fontDatas, c)
+	})
+	if err != nil {
+		return fmt.Errorf("embed ass error when write to writer: %w", err)
+	}
+	return nil
+}
 
+// 将字幕内容写入 writer，默认保留原有的嵌入字体
+func (ap *ASSParser) Write(writer io.Writer, opts ...WriteOption) error {
+	opts = append([]WriteOption{WithFontMergePolicy(FontMergeKeep)}, opts...)
+	return ap.WriteWithEmbeddedFonts(nil, writer, opts...)
+}
+
+// 依次返回 Contents 中的行，没有更多内容时返回 io.EOF
+func (ap *ASSParser) contentsReader() func() (ContentInfo, error) {
+	i := 0
+	return func() (ContentInfo, error) {
+		if i >= len(ap.Contents) {
+			return ContentInfo{}, io.EOF
+		}
+		i++
+		return ap.Contents[i-1], nil
+	}
+}
+
+// 逐行写入字幕内容，next 依次返回不包括嵌入区块内容的原始行，没有更多内容时返回 io.EOF
+// writeFonts 在第一个 [Fonts] 行或 [Events] 行之前调用，header 为区块标题行
+// hasGraphics 为原始内容中是否有 [Graphics] 区块，没有时在 [Events] 之前写入 [Graphics] 区块
+// 按原始内容还原 BOM 与换行符，并转换为 enc 指定的编码
+func (ap *ASSParser) write(writer io.Writer, enc TextEncoding, next func() (ContentInfo, error), hasGraphics bool, writeFonts func(lw *lineWriter, header ContentInfo) error) error {
+	if bom := enc.bom(); ap.bom && bom != nil { // 其他编码没有 BOM，不写入
+		if _, err := writer.Write(bom); err != nil {
+			return err
+		}
+	}
+	ew := encodeWriter(writer, enc)
+	lw := &lineWriter{w: ew, eol: ap.lineEnding}
+	if lw.eol == "" {
+		lw.eol = "\n"
+	}
+
+	// [Script Info] 按 ScriptInfo 重新输出，未修改的行根据原始文本找回原有的换行符
+	// 区块末尾的空行不由 ScriptInfo 输出，按原样保留
+	infoEOLs := make(map[string]string)
+	infoTrailing := make(map[uint]bool)
+	inInfo := false
+	var blanks []uint
 	for _, ci := range ap.Contents {
-		if !insertedFonts && strings.ToLower(strings.TrimSpace(ci.RawContent)) == "[events]" {
-			if _, err = writer.Write([]byte("[Fonts]")); err != nil {
-				goto fail
+		switch {
+		case startWith(ci.RawContent, "["):
+			for _, lineNum := range blanks {
+				infoTrailing[lineNum] = true
+			}
+			blanks = nil
+			inInfo = startWith(ci.RawContent, "[Script Info]")
+		case !inInfo:
+		case strings.TrimSpace(ci.RawContent) == "":
+			blanks = append(blanks, ci.LineNum)
+			infoEOLs[ci.RawContent] = ci.eol
+		default:
+			blanks = nil
+			infoEOLs[ci.RawContent] = ci.eol
+		}
+	}
+	for _, lineNum := range blanks {
+		infoTrailing[lineNum] = true
+	}
+
+	var s parseState
+	var wroteStyles, wroteEvents bool
+	var wroteFonts, wroteGraphics bool
+	for {
+		ci, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		// 多个 [Fonts] 或 [Graphics] 区块的内容合并写入第一个区块的位置
+		switch {
+		case isHeader(ci, "[fonts]"):
+			if !wroteFonts {
+				if err := writeFonts(lw, ci); err != nil {
+					return err
+				}
+				wroteFonts = true
 			}
-			if len(fontDatas) > 0 { // 如果有字体数据需要嵌入
-				// 对字体名称进行排序以确保输出的确定性
-				var fontNames []string
-				for fontName := range fontDatas {
-					fontNames = append(fontNames, fontName)
+			s = parseState{}
+			continue
+		case isHeader(ci, "[graphics]"):
+			if !wroteGraphics {
+				if err := ap.writeGraphics(lw, ci); err != nil {
+					return err
 				}
-				sort.Strings(fontNames)
-
-				// 按排序后的顺序写入字体
-				for _, fontName := range fontNames {
-					fontData := fontDatas[fontName]
-					if _, err = writer.Write([]byte("\nfontname: " + fontName + "\n")); err != nil {
-						goto fail
-					}
-					if err = UUEncode(fontData, writer, true); err != nil {
-						goto fail
-					}
+				wroteGraphics = true
+			}
+			s = parseState{}
+			continue
+		case !wroteFonts && isHeader(ci, "[events]"):
+			if err := writeFonts(lw, ContentInfo{RawContent: "[Fonts]"}); err != nil {
+				return err
+			}
+			wroteFonts = true
+		}
+		if !hasGraphics && !wroteGraphics && isHeader(ci, "[events]") {
+			if err := ap.writeGraphics(lw, ContentInfo{RawContent: "[Graphics]"}); err != nil {
+				return err
+			}
+			wroteGraphics = true
+		}
+		if !ap.parsed { // 未建立样式表与事件表，原样输出
+			lw.content(ci)
+			continue
+		}
+
+		lines := []ContentInfo{ci}
+		switch {
+		case startWith(ci.RawContent, "[Script Info]"):
+			s = parseState{inScriptInfoSection: true}
+			for _, line := range ap.ScriptInfo.Lines() {
+				lines = append(lines, ContentInfo{RawContent: line, eol: infoEOLs[line]})
+			}
+		case startWith(ci.RawContent, "[V4+ Styles]"), startWith(ci.RawContent, "[V4 Styles]"):
+			s = parseState{inStyleSection: true}
+		case startWith(ci.RawContent, "[Events]"):
+			s = parseState{inEventSection: true}
+		case startWith(ci.RawContent, "["):
+			s = parseState{}
+
+		case s.inScriptInfoSection && !infoTrailing[ci.LineNum]:
+			lines = nil // 已按 ScriptInfo 输出
+
+		case s.inStyleSection && startWith(ci.RawContent, "Format:"):
+			if !wroteStyles {
+				format := ap.StyleTable.format()
+				for _, si := range ap.StyleTable.rows {
+					lines = append(lines, rowContent(si.content, si.line(format)))
 				}
-				if _, err = writer.Write([]byte("\n")); err != nil {
-					goto fail
+				wroteStyles = true
+			}
+		case s.inStyleSection && startWith(ci.RawContent, "Style:"):
+			lines = nil // 已按样式表输出
+
+		case s.inEventSection && startWith(ci.RawContent, "Format:"):
+			if !wroteEvents {
+				format := ap.EventTable.format()
+				for _, di := range ap.EventTable.rows {
+					lines = append(lines, rowContent(di.content, di.line(format)))
 				}
+				wroteEvents = true
 			}
-			insertedFonts = true
+		case s.inEventSection && (startWith(ci.RawContent, "Dialogue:") || startWith(ci.RawContent, "Comment:")):
+			lines = nil // 已按事件表输出
 		}
-		if _, err = writer.Write([]byte(ci.RawContent + "\n")); err != nil {
-			goto fail
+
+		for _, line := range lines {
+			lw.content(line)
 		}
 	}
-	return nil
-
-fail:
-	return fmt.Errorf("embed ass error when write to writer: %w", err)
+	if err := lw.close(!ap.noFinalEOL); err != nil {
+		return err
+	}
+	return ew.Close()
 }
 
 // 将 ASS 内容转换为 SRT 格式并写入指定的 Writer
 func (ap *ASSParser) ToSRT(writer io.Writer) error {
 	for i, di := range ap.EventTable.rows {
-		_, err := fmt.Fprintf(
+		var lineNum uint
+		if di.content != nil {
+			lineNum = di.content.LineNum
+		}
+		start, err := di.Start()
+		if err != nil {
+			return fmt.Errorf("failed to convert start time at ASS line %d: %w", lineNum, err)
+		}
+		end, err := di.End()
+		if err != nil {
+			return fmt.Errorf("failed to convert end time at ASS line %d: %w", lineNum, err)
+		}
+		_, err = fmt.Fprintf(
 			writer,
 			"%d\n%s --> %s\n%s\n\n",
 			i+1,
-			di.Fields["Start"],
-			di.Fields["End"],
+			start.SRTString(),
+			end.SRTString(),
 			CleanEffects(di.Fields["Text"]),
 		)
 		if err != nil {
-			return fmt.Errorf("failed to write SRT content at ASS line %d: %w", di.content.LineNum, err)
+			return fmt.Errorf("failed to write SRT content at ASS line %d: %w", lineNum, err)
 		}
 	}
 	return nil
@@ -116,6 +116,68 @@ var styleOverrideTestCases = []StyleOverrideTestCase{
 			Italic:   70,
 		},
 	},
+	{
+		name: `@竖排字体`,
+		code: `\fn@微软雅黑\frz-90`,
+		origin: ass.FontDesc{
+			FontName: "黑体",
+			Bold:     400,
+			Italic:   0,
+		},
+		expect: ass.FontDesc{
+			FontName: "微软雅黑",
+			Bold:     400,
+			Italic:   0,
+			Vertical: true,
+		},
+	},
+	{
+		name: `竖排样式恢复横排`,
+		code: `\fn宋体`,
+		origin: ass.FontDesc{
+			FontName: "黑体",
+			Bold:     400,
+			Italic:   0,
+			Vertical: true,
+		},
+		expect: ass.FontDesc{
+			FontName: "宋体",
+			Bold:     400,
+			Italic:   0,
+		},
+	},
+	{
+		name: `\fe修改字符集`,
+		code: `\fe128\fnＭＳ 明朝`,
+		origin: ass.FontDesc{
+			FontName: "黑体",
+			Bold:     400,
+			Italic:   0,
+			Charset:  134,
+		},
+		expect: ass.FontDesc{
+			FontName: "ＭＳ 明朝",
+			Bold:     400,
+			Italic:   0,
+			Charset:  128,
+		},
+	},
+	{
+		name: `\fe无参数恢复样式字符集`,
+		code: `\fe1\fe`,
+		origin: ass.FontDesc{
+			FontName: "黑体",
+			Bold:     400,
+			Italic:   0,
+			Charset:  134,
+		},
+		expect: ass.FontDesc{
+			FontName: "黑体",
+			Bold:     400,
+			Italic:   0,
+			Charset:  134,
+		},
+	},
 }
 
 type ParseDialogueTestCase struct {
@@ -540,6 +602,67 @@ var parseDialogueTestCases = []ParseDialogueTestCase{
 			},
 		},
 	},
+	{
+		name: "绘图模式",
+		d: ass.DialogueInfo{
+			Fields: map[string]string{
+				"Layer":   "0",
+				"Start":   "0:00:00.00",
+				"End":     "0:00:05.00",
+				"Style":   "style1",
+				"Name":    "",
+				"MarginL": "0",
+				"MarginR": "0",
+				"MarginV": "0",
+				"Effect":  "",
+				"Text":    `文{\p1}m 0 0 l 100 0 100 100 0 100{\p0\fnA}字{\p2\fnB}b 1 2 3 4 5 6{\p0}尾`,
+			},
+		},
+		fd: map[string]ass.FontDesc{
+			"style1": {FontName: "楷体", Bold: 400, Italic: 0},
+		},
+		expect: map[ass.FontDesc]ass.CodepointSet{
+			{FontName: "楷体", Bold: 400, Italic: 0}: {
+				'文': {},
+			},
+			{FontName: "A", Bold: 400, Italic: 0}: {
+				'字': {},
+			},
+			{FontName: "B", Bold: 400, Italic: 0}: {
+				'尾': {},
+			},
+		},
+	},
+	{
+		name: "\\t 中内嵌的标签不改变状态",
+		d: ass.DialogueInfo{
+			Fields: map[string]string{
+				"Layer":   "0",
+				"Start":   "0:00:00.00",
+				"End":     "0:00:05.00",
+				"Style":   "style1",
+				"Name":    "",
+				"MarginL": "0",
+				"MarginR": "0",
+				"MarginV": "0",
+				"Effect":  "",
+				"Text":    `{\t(\fnBogus\p1)}abc{\t(0,500,\b1\r\fe128)\fnA}字`,
+			},
+		},
+		fd: map[string]ass.FontDesc{
+			"style1": {FontName: "楷体", Bold: 400, Italic: 0},
+		},
+		expect: map[ass.FontDesc]ass.CodepointSet{
+			{FontName: "楷体", Bold: 400, Italic: 0}: {
+				'a': {},
+				'b': {},
+				'c': {},
+			},
+			{FontName: "A", Bold: 400, Italic: 0}: {
+				'字': {},
+			},
+		},
+	},
 }
 
 func TestStyleOverride(t *testing.T) {
@@ -584,6 +707,226 @@ func TestParseDialogue(t *testing.T) {
 
 }
 
+const parseASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Title,宋体,24,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,1,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Comment: 0,0:00:00.00,0:00:05.00,Title,,0,0,0,,注释
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,你好
+Dialogue: 0,0:00:05.00,0:00:10.00,Missing,,0,0,0,,丢失
+`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []ass.ParserOption
+		expect map[ass.FontDesc]ass.CodepointSet
+	}{
+		{
+			name: "跳过注释",
+			expect: map[ass.FontDesc]ass.CodepointSet{
+				{FontName: "楷体", Bold: 400, Italic: 0}: {'你': {}, '好': {}},
+			},
+		},
+		{
+			name: "包括注释",
+			opts: []ass.ParserOption{ass.WithComments()},
+			expect: map[ass.FontDesc]ass.CodepointSet{
+				{FontName: "楷体", Bold: 400, Italic: 0}: {'你': {}, '好': {}},
+				{FontName: "宋体", Bold: 700, Italic: 0}: {'注': {}, '释': {}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(parseASSContent), tc.opts...)
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			require.Equal(t, tc.expect, ap.FontSets)
+
+			// 未定义的样式不会中断解析
+			require.Equal(t, []ass.Diagnostic{{
+				Severity: ass.SeverityError,
+				LineNum:  13,
+				Column:   35,
+				Code:     ass.CodeUnknownStyle,
+				Message:  `style "Missing" not found`,
+			}}, ap.Diagnostics)
+		})
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	content := strings.Replace(parseASSContent, "你好", `{\b-5\rMissing}你好`, 1)
+	content = strings.Replace(content, "&H80000000,1,0,", "&H80000000,bold,0,", 1)
+
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	require.Len(t, ap.Diagnostics, 4)
+	require.Equal(t, ass.CodeInvalidBold, ap.Diagnostics[0].Code)
+	require.Equal(t, uint(7), ap.Diagnostics[0].LineNum)
+	require.Equal(t, uint(64), ap.Diagnostics[0].Column)
+
+	// 样式覆盖段中的诊断信息指向对应标签
+	require.Equal(t, ass.Diagnostic{
+		Severity: ass.SeverityWarning,
+		LineNum:  12,
+		Column:   52,
+		Code:     ass.CodeInvalidBold,
+		Message:  `invalid bold value "-5"`,
+	}, ap.Diagnostics[1])
+	require.Equal(t, ass.CodeUnknownStyle, ap.Diagnostics[2].Code)
+	require.Equal(t, uint(56), ap.Diagnostics[2].Column)
+
+	// 严格模式下警告视为错误
+	ap, err = ass.NewASSParser(strings.NewReader(content), ass.WithStrict())
+	require.NoError(t, err)
+	var d ass.Diagnostic
+	require.ErrorAs(t, ap.Parse(), &d)
+	require.Equal(t, ass.CodeInvalidBold, d.Code)
+}
+
+// 样式名为空时视为 Default
+func TestParseEmptyStyleName(t *testing.T) {
+	content := strings.Replace(parseASSContent, "Style: Default,", "Style: ,", 1)
+	content = strings.Replace(content, ",Default,,0,0,0,,你好", ",,,0,0,0,,你好", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	require.NotNil(t, ap.StyleTable.Get("Default"))
+	fd := ap.StyleTable.GetFontDescByName("Default")
+	require.NotNil(t, fd)
+	require.Equal(t, "楷体_400_0", fd.String())
+	require.Equal(t, map[ass.FontDesc]ass.CodepointSet{
+		{FontName: "楷体", Bold: 400, Italic: 0}: {'你': {}, '好': {}},
+	}, ap.FontSets)
+
+	// 写出时保留原始的空样式名
+	var b strings.Builder
+	require.NoError(t, ap.Write(&b))
+	require.Equal(t, content, b.String())
+}
+
+func TestParseStyleFallback(t *testing.T) {
+	content := strings.Replace(parseASSContent, "你好", `{\rTitle}你{\rMissing}好`, 1)
+	testCases := []struct {
+		name    string
+		content string
+		expect  map[ass.FontDesc]ass.CodepointSet
+		message string
+	}{
+		{
+			name:    "回退到Default样式",
+			content: content,
+			expect: map[ass.FontDesc]ass.CodepointSet{
+				{FontName: "宋体", Bold: 700, Italic: 0}: {'你': {}},
+				{FontName: "楷体", Bold: 400, Italic: 0}: {'好': {}, '丢': {}, '失': {}},
+			},
+			message: `style "Missing" not found, fall back to style "Default"`,
+		},
+		{
+			name:    "回退到内置样式",
+			content: strings.ReplaceAll(content, "Default,", "Main,"),
+			expect: map[ass.FontDesc]ass.CodepointSet{
+				{FontName: "宋体", Bold: 700, Italic: 0}:    {'你': {}},
+				{FontName: "楷体", Bold: 400, Italic: 0}:    {'好': {}},
+				{FontName: "Arial", Bold: 400, Italic: 0}: {'丢': {}, '失': {}},
+			},
+			message: `style "Missing" not found, fall back to built-in font "Arial"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(tc.content), ass.WithStyleFallback())
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			require.Equal(t, tc.expect, ap.FontSets)
+
+			// \r 引用未定义的样式时重置为初始样式，回退均记录为警告
+			require.Len(t, ap.Diagnostics, 2)
+			require.Equal(t, ass.SeverityWarning, ap.Diagnostics[0].Severity)
+			require.Equal(t, `style "Missing" not found, reset to the initial style`, ap.Diagnostics[0].Message)
+			require.Equal(t, ass.Diagnostic{
+				Severity: ass.SeverityWarning,
+				LineNum:  13,
+				Column:   35,
+				Code:     ass.CodeUnknownStyle,
+				Message:  tc.message,
+			}, ap.Diagnostics[1])
+		})
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	content := strings.Replace(parseASSContent, "10,10,10,1\nStyle: Title", "10,10,10,134\nStyle: Title", 1)
+	content = strings.Replace(content, "你好", `你{\fe128}好`, 1)
+
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	gb2312 := ass.FontDesc{FontName: "楷体", Bold: 400, Italic: 0, Charset: 134}
+	require.Equal(t, map[ass.FontDesc]ass.CodepointSet{
+		gb2312: {'你': {}},
+		{FontName: "楷体", Bold: 400, Italic: 0, Charset: 128}: {'好': {}},
+	}, ap.FontSets)
+	require.Equal(t, "楷体_400_0_c134", gb2312.String())
+}
+
+// 样式字体带有前缀 @ 时为竖排字体
+func TestParseVertical(t *testing.T) {
+	content := strings.Replace(parseASSContent, "Default,楷体", "Default,@楷体", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	vertical := ass.FontDesc{FontName: "楷体", Bold: 400, Italic: 0, Vertical: true}
+	require.Contains(t, ap.FontSets, vertical)
+	require.Equal(t, "楷体_400_0_v", vertical.String())
+}
+
+func TestParseRenderer(t *testing.T) {
+	content := strings.Replace(parseASSContent, "你好", `你\h好\n{\q2}啊\n\N`+"\t呀", 1)
+	testCases := []struct {
+		name     string
+		renderer ass.Renderer
+		expect   ass.CodepointSet
+	}{
+		{
+			name:     "不模拟渲染器",
+			renderer: ass.RendererNone,
+			expect:   ass.CodepointSet{'你': {}, '好': {}, '啊': {}, '\t': {}, '呀': {}},
+		},
+		{
+			name:     "libass",
+			renderer: ass.RendererLibass,
+			expect:   ass.CodepointSet{'你': {}, '\u00A0': {}, '好': {}, ' ': {}, '啊': {}, '呀': {}},
+		},
+		{
+			name:     "VSFilter",
+			renderer: ass.RendererVSFilter,
+			expect:   ass.CodepointSet{'你': {}, '\u00A0': {}, '好': {}, ' ': {}, '啊': {}, '\t': {}, '呀': {}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(content), ass.WithRenderer(tc.renderer))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			require.Equal(t, tc.expect, ap.FontSets[ass.FontDesc{FontName: "楷体", Bold: 400, Italic: 0}])
+		})
+	}
+}
+
 func generateASSContent(dialogueCount int) string {
 	chineseWords := []string{
 		"世界", "生活", "时间", "朋友", "工作", "学习", "梦想", "希望", "快乐", "美好",
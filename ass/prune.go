@@ -0,0 +1,97 @@
+package ass
+
+import (
+	"slices"
+)
+
+// 清理样式表的结果报告
+type PruneReport struct {
+	Removed []string          // 因未被引用而删除的样式
+	Merged  map[string]string // 与其他样式完全相同而被合并的样式，原名称->保留的样式名
+}
+
+// 返回未被 events 的 Style 字段或 \r 标签引用的样式名，按样式表中的顺序排列
+// Comment 行同样视为引用；事件引用了未定义的样式时渲染器会回退到 Default 样式，此时 Default 视为被引用
+func (st *StyleTable) Unused(events []*DialogueInfo) []string {
+	used := st.usedStyles(events, true)
+	names := make([]string, 0)
+	for _, si := range st.rows {
+		if _, ok := used[si.Name()]; !ok && !slices.Contains(names, si.Name()) {
+			names = append(names, si.Name())
+		}
+	}
+	return names
+}
+
+// 删除未被 events 引用的样式
+// 使用 WithDeduplicate 时先合并除名称外完全相同的样式，并修改 events 中对被合并样式的引用
+func (st *StyleTable) Prune(events []*DialogueInfo, opts ...PruneOption) *PruneReport {
+	c := &pruneConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	report := &PruneReport{Removed: make([]string, 0), Merged: make(map[string]string)}
+	if c.dedupe {
+		st.dedupe(events, report)
+	}
+	report.Removed = st.Unused(events)
+	st.rows = slices.DeleteFunc(st.rows, func(si *StyleInfo) bool {
+		return slices.Contains(report.Removed, si.Name())
+	})
+	return report
+}
+
+// 合并除名称外完全相同的样式，保留先出现的样式
+// Default 与其他样式相同时保留 Default，以免改变渲染器回退时使用的样式
+// 被之后的同名样式覆盖的样式不参与合并
+func (st *StyleTable) dedupe(events []*DialogueInfo, report *PruneReport) {
+	type survivor struct {
+		si    *StyleInfo
+		style *Style
+	}
+	survivors := make([]survivor, 0, len(st.rows))
+	rows := make([]*StyleInfo, 0, len(st.rows))
+	for _, si := range st.rows {
+		name := si.Name()
+		style, err := si.Style()
+		if err != nil || st.Get(name) != si {
+			rows = append(rows, si)
+			continue
+		}
+
+		idx := slices.IndexFunc(survivors, func(s survivor) bool {
+			return sameStyle(s.style, style)
+		})
+		switch {
+		case idx < 0:
+			survivors = append(survivors, survivor{si: si, style: style})
+			rows = append(rows, si)
+		case name == defaultFontName:
+			merged := survivors[idx].si
+			for from, to := range report.Merged {
+				if to == merged.Name() {
+					report.Merged[from] = name
+				}
+			}
+			report.Merged[merged.Name()] = name
+			rows = slices.DeleteFunc(rows, func(r *StyleInfo) bool { return r == merged })
+			rows = append(rows, si)
+			survivors[idx] = survivor{si: si, style: style}
+		default:
+			report.Merged[name] = survivors[idx].si.Name()
+		}
+	}
+	st.rows = rows
+
+	for _, di := range events {
+		renameStyleRefs(di, report.Merged)
+	}
+}
+
+// 删除未被事件引用的样式，并重新统计 FontSets
+func (ap *ASSParser) PruneStyles(opts ...PruneOption) *PruneReport {
+	report := ap.StyleTable.Prune(ap.EventTable.rows, opts...)
+	ap.CollectFontSets()
+	return report
+}
@@ -0,0 +1,84 @@
+package ass_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const pruneASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Main,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+Style: Sign2,黑体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+Style: Unused,宋体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Main,,0,0,0,,对白
+Comment: 0,0:00:00.00,0:00:05.00,Sign2,,0,0,0,,招牌{\rSign}说明
+`
+
+func TestPruneStyles(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   []ass.PruneOption
+		report *ass.PruneReport
+		styles []string
+		events []string
+	}{
+		{
+			name:   "删除未使用的样式",
+			report: &ass.PruneReport{Removed: []string{"Default", "Unused"}, Merged: map[string]string{}},
+			styles: []string{"Main", "Sign", "Sign2"},
+			events: []string{
+				"Dialogue: 0,0:00:00.00,0:00:05.00,Main,,0,0,0,,对白",
+				`Comment: 0,0:00:00.00,0:00:05.00,Sign2,,0,0,0,,招牌{\rSign}说明`,
+			},
+		},
+		{
+			name:   "合并相同的样式",
+			opts:   []ass.PruneOption{ass.WithDeduplicate()},
+			report: &ass.PruneReport{Removed: []string{"Unused"}, Merged: map[string]string{"Main": "Default", "Sign2": "Sign"}},
+			styles: []string{"Default", "Sign"},
+			events: []string{
+				"Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,对白",
+				`Comment: 0,0:00:00.00,0:00:05.00,Sign,,0,0,0,,招牌{\rSign}说明`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(pruneASSContent))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+
+			require.Equal(t, tc.report, ap.PruneStyles(tc.opts...))
+			styles := make([]string, 0)
+			for _, si := range ap.StyleTable.Styles() {
+				styles = append(styles, si.Name())
+			}
+			require.Equal(t, tc.styles, styles)
+
+			var b strings.Builder
+			require.NoError(t, ap.Write(&b))
+			for _, event := range tc.events {
+				require.Contains(t, b.String(), event+"\n")
+			}
+		})
+	}
+
+	// 引用未定义的样式时保留 Default
+	ap, err := ass.NewASSParser(strings.NewReader(pruneASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	events := []*ass.DialogueInfo{ass.NewDialogueInfo(map[string]string{"Style": "Missing"}, nil)}
+	require.Equal(t, []string{"Main", "Sign", "Sign2", "Unused"}, ap.StyleTable.Unused(events))
+}
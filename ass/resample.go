@@ -0,0 +1,237 @@
+package ass
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 改变脚本分辨率时，源分辨率与目标分辨率宽高比不同的处理方式
+type AspectMode uint8
+
+const (
+	AspectStretch       AspectMode = iota // 拉伸，横向与纵向分别缩放
+	AspectAddBorders                      // 保持宽高比，在两侧或上下增加边框
+	AspectRemoveBorders                   // 保持宽高比，裁去两侧或上下的内容
+)
+
+// 缩放时使用的比例与偏移
+type resampleState struct {
+	rx, ry    float64 // 横向与纵向的缩放比例
+	ar        float64 // 额外的横向拉伸比例，作用于 \fscx 与样式的 ScaleX
+	left, top float64 // 源分辨率左侧与上方增加的边距，裁剪时为负数
+}
+
+// 与 Aegisub 一致，宽高比相差不超过 1% 时不做处理
+func newResampleState(srcX int, srcY int, dstX int, dstY int, mode AspectMode) *resampleState {
+	sx, sy := float64(srcX), float64(srcY)
+	oldAR, newAR := sx/sy, float64(dstX)/float64(dstY)
+	st := &resampleState{ar: 1}
+	if math.Abs(oldAR-newAR)/newAR > 0.01 {
+		horizontal := newAR > oldAR // 是否在两侧增加边框
+		switch mode {
+		case AspectRemoveBorders:
+			horizontal = !horizontal
+			fallthrough
+		case AspectAddBorders:
+			if horizontal {
+				st.left = (sy*newAR - sx) / 2
+			} else {
+				st.top = (sx/newAR - sy) / 2
+			}
+		case AspectStretch:
+			st.ar = newAR / oldAR
+		}
+	}
+	st.rx = float64(dstX) / (sx + st.left*2)
+	st.ry = float64(dstY) / (sy + st.top*2)
+	return st
+}
+
+// 横坐标
+func (st *resampleState) x(v float64) float64 {
+	return (v + st.left) * st.rx
+}
+
+// 纵坐标
+func (st *resampleState) y(v float64) float64 {
+	return (v + st.top) * st.ry
+}
+
+// 横向尺寸
+func (st *resampleState) width(v float64) float64 {
+	return v * st.rx
+}
+
+// 纵向尺寸，字号、边框与阴影等均按纵向比例缩放
+func (st *resampleState) height(v float64) float64 {
+	return v * st.ry
+}
+
+// 将脚本分辨率（PlayResX 与 PlayResY）修改为 x*y，与 Aegisub 的“调整脚本分辨率”相同
+// 样式的字号、边框、阴影、字间距与边距，以及对话中的边距、坐标、\fs、\bord 等标签与绘图坐标均按比例缩放
+// 原分辨率按 ScriptInfo.PlayRes 计算，存在无法解析的样式时不做任何修改并返回错误
+func (ap *ASSParser) Resample(x int, y int, opts ...ResampleOption) error {
+	if x <= 0 || y <= 0 {
+		return fmt.Errorf("invalid resolution %dx%d", x, y)
+	}
+	c := &resampleConfig{aspect: AspectStretch}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	styles := make([]*Style, 0, len(ap.StyleTable.rows))
+	for _, si := range ap.StyleTable.rows {
+		style, err := si.Style()
+		if err != nil {
+			return fmt.Errorf("failed to resample style \"%s\": %w", si.Name(), err)
+		}
+		styles = append(styles, style)
+	}
+
+	srcX, srcY := ap.ScriptInfo.PlayRes()
+	st := newResampleState(srcX, srcY, x, y, c.aspect)
+	for i, si := range ap.StyleTable.rows {
+		st.resampleStyle(styles[i])
+		si.SetStyle(styles[i])
+	}
+	for _, di := range ap.EventTable.rows {
+		st.resampleEvent(di)
+	}
+	ap.ScriptInfo.PlayResX, ap.ScriptInfo.PlayResY = x, y
+	return nil
+}
+
+func (st *resampleState) resampleStyle(s *Style) {
+	s.FontSize = float64(roundInt(st.height(s.FontSize)))
+	s.Outline = roundNumber(st.height(s.Outline))
+	s.Shadow = roundNumber(st.height(s.Shadow))
+	s.Spacing = roundNumber(st.width(s.Spacing))
+	s.ScaleX = roundNumber(s.ScaleX * st.ar)
+	s.MarginL = roundInt(st.x(float64(s.MarginL)))
+	s.MarginR = roundInt(st.x(float64(s.MarginR)))
+	s.MarginV = roundInt(st.y(float64(s.MarginV)))
+}
+
+func (st *resampleState) resampleEvent(di *DialogueInfo) {
+	// 为 0 的边距表示使用样式的边距，保持不变
+	for key, fn := range map[string]func(float64) float64{"MarginL": st.x, "MarginR": st.x, "MarginV": st.y} {
+		if v, err := strconv.Atoi(di.Fields[key]); err == nil && v != 0 {
+			di.Fields[key] = strconv.Itoa(roundInt(fn(float64(v))))
+		}
+	}
+	if text, ok := di.Fields["Text"]; ok {
+		di.Fields["Text"] = st.resampleText(text)
+	}
+}
+
+// 缩放对话文本中的标签参数与绘图坐标
+func (st *resampleState) resampleText(text string) string {
+	segments := tags.ParseText(text)
+	drawing := false
+	for i := range segments {
+		seg := &segments[i]
+		if seg.Block == nil {
+			if drawing { // 绘图同样受 \fscx 影响，横纵坐标均按纵向比例缩放
+				seg.Text = scaleDrawing(seg.Text, st.height, st.height)
+			}
+			continue
+		}
+		seg.Block.Walk(func(tag *tags.Tag) bool {
+			if tag.Kind == tags.KindTag {
+				st.resampleTag(tag)
+			}
+			return true
+		})
+		if p := seg.Block.Last("p"); p != nil {
+			if scale, err := strconv.Atoi(p.Arg()); err == nil {
+				drawing = scale > 0
+			}
+		}
+	}
+	return tags.JoinText(segments)
+}
+
+func (st *resampleState) resampleTag(tag *tags.Tag) {
+	switch tag.Name {
+	case "pos", "org": // \pos(x,y)
+		mapArgs(tag, st.x, 0)
+		mapArgs(tag, st.y, 1)
+	case "move": // \move(x1,y1,x2,y2[,t1,t2])
+		mapArgs(tag, st.x, 0, 2)
+		mapArgs(tag, st.y, 1, 3)
+	case "clip", "iclip":
+		if len(tag.Args) == 4 { // \clip(x1,y1,x2,y2)
+			mapArgs(tag, st.x, 0, 2)
+			mapArgs(tag, st.y, 1, 3)
+			break
+		}
+		if len(tag.Args) == 0 {
+			break
+		}
+		// \clip([scale,]drawing)，坐标单位为 1/2^(scale-1) 像素
+		factor := 1.0
+		if len(tag.Args) == 2 {
+			if scale, err := strconv.Atoi(tag.Args[0]); err == nil && scale > 1 {
+				factor = math.Pow(2, float64(scale-1))
+			}
+		}
+		last := len(tag.Args) - 1
+		tag.Args[last] = scaleDrawing(tag.Args[last],
+			func(v float64) float64 { return st.x(v/factor) * factor },
+			func(v float64) float64 { return st.y(v/factor) * factor })
+	case "fs", "bord", "shad", "ybord", "yshad", "blur", "pbo":
+		mapArgs(tag, st.height, 0)
+	case "fsp", "xbord", "xshad":
+		mapArgs(tag, st.width, 0)
+	case "fscx":
+		mapArgs(tag, func(v float64) float64 { return v * st.ar }, 0)
+	}
+}
+
+// 对标签中指定位置的数值参数应用 fn，无法解析的参数保持不变
+func mapArgs(tag *tags.Tag, fn func(float64) float64, indexes ...int) {
+	for _, i := range indexes {
+		if i >= len(tag.Args) {
+			continue
+		}
+		if v, err := strconv.ParseFloat(tag.Args[i], 64); err == nil {
+			tag.Args[i] = strconv.FormatFloat(roundNumber(fn(v)), 'f', -1, 64)
+		}
+	}
+}
+
+// 缩放绘图指令中的坐标，坐标按 x、y 交替出现
+// VSFilter 只支持整数坐标，缩放后的坐标四舍五入为整数
+func scaleDrawing(drawing string, fx func(float64) float64, fy func(float64) float64) string {
+	fields := strings.Fields(drawing)
+	isX := true
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil { // 绘图命令
+			isX = true
+			continue
+		}
+		if isX {
+			v = fx(v)
+		} else {
+			v = fy(v)
+		}
+		fields[i] = strconv.Itoa(roundInt(v))
+		isX = !isX
+	}
+	return strings.Join(fields, " ")
+}
+
+// 保留 3 位小数，避免浮点误差产生过长的数值
+func roundNumber(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// 四舍五入为整数，先消除浮点误差以保证 x.5 总是进位
+func roundInt(v float64) int {
+	return int(math.Round(roundNumber(v)))
+}
@@ -0,0 +1,113 @@
+package ass_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const resampleASSContent = `[Script Info]
+ScriptType: v4.00+
+PlayResX: %x
+PlayResY: %y
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,48,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,2,0,1,2,1,2,10,10,20,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,40,0,,{\pos(320,240)\fs30\bord2\fscx80\t(0,500,\fs40)}你好{\p1}m 0 0 l 100 0 100 100{\p0}
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\move(0,0,640,480)\clip(0,0,320,240)}{\iclip(2,m 0 0 l 640 0)}世界
+`
+
+func TestResample(t *testing.T) {
+	testCases := []struct {
+		name    string
+		x, y    string
+		mode    ass.AspectMode
+		style   string
+		events  []string
+		expectX int
+		expectY int
+	}{
+		{
+			name:  "等比放大",
+			x:     "640",
+			y:     "480",
+			mode:  ass.AspectStretch,
+			style: "Style: Default,楷体,144,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,6,0,1,6,3,2,30,30,60,1",
+			events: []string{
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,120,0,,{\pos(960,720)\fs90\bord6\fscx80\t(0,500,\fs120)}你好{\p1}m 0 0 l 300 0 300 300{\p0}`,
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\move(0,0,1920,1440)\clip(0,0,960,720)}{\iclip(2,m 0 0 l 1920 0)}世界`,
+			},
+			expectX: 1920,
+			expectY: 1440,
+		},
+		{
+			name:  "拉伸",
+			x:     "640",
+			y:     "480",
+			mode:  ass.AspectStretch,
+			style: "Style: Default,楷体,108,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,133.333,100,6,0,1,4.5,2.25,2,30,30,45,1",
+			events: []string{
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,120,0,,{\pos(960,540)\fs67.5\bord4.5\fscx106.667\t(0,500,\fs90)}你好{\p1}m 0 0 l 225 0 225 225{\p0}`,
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\move(0,0,1920,1080)\clip(0,0,960,540)}{\iclip(2,m 0 0 l 1920 0)}世界`,
+			},
+			expectX: 1920,
+			expectY: 1080,
+		},
+		{
+			name:  "增加边框",
+			x:     "640",
+			y:     "480",
+			mode:  ass.AspectAddBorders,
+			style: "Style: Default,楷体,108,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,4.5,0,1,4.5,2.25,2,263,263,45,1",
+			events: []string{
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,330,0,,{\pos(960,540)\fs67.5\bord4.5\fscx80\t(0,500,\fs90)}你好{\p1}m 0 0 l 225 0 225 225{\p0}`,
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\move(240,0,1680,1080)\clip(240,0,960,540)}{\iclip(2,m 480 0 l 1920 0)}世界`,
+			},
+			expectX: 1920,
+			expectY: 1080,
+		},
+		{
+			name:  "裁去边框",
+			x:     "640",
+			y:     "480",
+			mode:  ass.AspectRemoveBorders,
+			style: "Style: Default,楷体,144,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,6,0,1,6,3,2,30,30,-120,1",
+			events: []string{
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,120,0,,{\pos(960,540)\fs90\bord6\fscx80\t(0,500,\fs120)}你好{\p1}m 0 0 l 300 0 300 300{\p0}`,
+				`Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,{\move(0,-180,1920,1260)\clip(0,-180,960,540)}{\iclip(2,m 0 -360 l 1920 -360)}世界`,
+			},
+			expectX: 1920,
+			expectY: 1080,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := strings.NewReplacer("%x", tc.x, "%y", tc.y).Replace(resampleASSContent)
+			ap, err := ass.NewASSParser(strings.NewReader(content))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+			require.NoError(t, ap.Resample(tc.expectX, tc.expectY, ass.WithAspectMode(tc.mode)))
+
+			require.Equal(t, tc.expectX, ap.ScriptInfo.PlayResX)
+			require.Equal(t, tc.expectY, ap.ScriptInfo.PlayResY)
+			var b strings.Builder
+			require.NoError(t, ap.Write(&b))
+			require.Contains(t, b.String(), tc.style+"\n")
+			for _, event := range tc.events {
+				require.Contains(t, b.String(), event+"\n")
+			}
+		})
+	}
+
+	ap, err := ass.NewASSParser(strings.NewReader(resampleASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	require.Error(t, ap.Resample(0, 1080))
+}
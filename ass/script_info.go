@@ -0,0 +1,316 @@
+package ass
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// [Script Info] 中的一项
+type ScriptInfoEntry struct {
+	Key       string // 键名，注释行为空
+	Value     string // 值，注释行为完整内容
+	raw       string // 原始文本
+	origKey   string // 解析时的键名
+	origValue string // 解析时的值
+}
+
+// 序列化为一行文本，未修改的项返回原始文本
+func (e *ScriptInfoEntry) String() string {
+	if e.raw != "" && e.Key == e.origKey && e.Value == e.origValue {
+		return e.raw
+	}
+	if e.Key == "" {
+		return e.Value
+	}
+	return e.Key + ": " + e.Value
+}
+
+// [Script Info] 区块
+type ScriptInfo struct {
+	Title                 string // 标题
+	OriginalScript        string // 原作者
+	ScriptType            string // 脚本类型，如 "v4.00+"
+	Collisions            string // 碰撞处理方式，Normal 或 Reverse
+	PlayResX              int    // 脚本分辨率宽度，0 表示未设置
+	PlayResY              int    // 脚本分辨率高度，0 表示未设置
+	LayoutResX            int    // 布局分辨率宽度，0 表示未设置
+	LayoutResY            int    // 布局分辨率高度，0 表示未设置
+	Timer                 string // 计时器速度
+	WrapStyle             int    // 换行方式
+	ScaledBorderAndShadow bool   // 边框与阴影是否随脚本分辨率缩放
+	YCbCrMatrix           string // 颜色矩阵，如 "TV.709"
+	Kerning               bool   // 是否启用字距调整
+
+	Others []ScriptInfoEntry // 未知的键与注释，按原顺序保存
+
+	order    []string                 // 原始的键顺序，重复的键每次出现都记录，未知的键与注释记为空字符串，空行记为 blankEntry
+	raws     map[string]scriptInfoRaw // 已知键最后一次出现时的原始内容
+	shadowed map[string][]string      // 已知键重复出现时，被之后同名键覆盖的原始文本
+	blanks   []string                 // 空行的原始文本，按原顺序保存
+}
+
+// order 中表示空行的标记，不会与任何键名相同
+const blankEntry = "\n"
+
+// 已知键的原始内容
+type scriptInfoRaw struct {
+	line    string // 原始文本
+	value   string // 解析后重新格式化的值，用于判断是否被修改；无法解析时为原始值
+	invalid bool   // 原始值是否无法解析
+}
+
+// 已知的键，按常见的书写顺序排列
+var scriptInfoKeys = []string{
+	"Title",
+	"Original Script",
+	"ScriptType",
+	"Collisions",
+	"PlayResX",
+	"PlayResY",
+	"LayoutResX",
+	"LayoutResY",
+	"Timer",
+	"WrapStyle",
+	"ScaledBorderAndShadow",
+	"YCbCr Matrix",
+	"Kerning",
+}
+
+func NewScriptInfo() *ScriptInfo {
+	return &ScriptInfo{
+		Others:   make([]ScriptInfoEntry, 0),
+		order:    make([]string, 0),
+		raws:     make(map[string]scriptInfoRaw),
+		shadowed: make(map[string][]string),
+		blanks:   make([]string, 0),
+	}
+}
+
+// 解析 [Script Info] 中的一行
+// 空行记录原有的位置，已知键的值无法解析时保留原始文本并返回错误
+func (si *ScriptInfo) parseLine(line string) error {
+	// ScriptType: v4.00+
+	// ; Script generated by Aegisub 3.3.3
+	if strings.TrimSpace(line) == "" {
+		si.blanks = append(si.blanks, line)
+		si.order = append(si.order, blankEntry)
+		return nil
+	}
+
+	entry := ScriptInfoEntry{Value: line, raw: line}
+	if !strings.HasPrefix(strings.TrimSpace(line), ";") {
+		if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+			entry.Key = strings.TrimSpace(parts[0])
+			entry.Value = strings.TrimSpace(parts[1])
+		}
+	}
+	entry.origKey, entry.origValue = entry.Key, entry.Value
+
+	key := canonicalScriptInfoKey(entry.Key)
+	if key == "" {
+		si.Others = append(si.Others, entry)
+		si.order = append(si.order, "")
+		return nil
+	}
+	if raw, ok := si.raws[key]; ok { // 重复的键以最后一次出现的值为准，之前的行原样保留
+		si.shadowed[key] = append(si.shadowed[key], raw.line)
+	}
+	si.order = append(si.order, key)
+	if err := si.set(key, entry.Value); err != nil {
+		si.raws[key] = scriptInfoRaw{line: line, value: entry.Value, invalid: true}
+		return err
+	}
+	value, _ := si.get(key)
+	si.raws[key] = scriptInfoRaw{line: line, value: value}
+	return nil
+}
+
+// 返回已知键的规范写法，未知键返回空字符串
+func canonicalScriptInfoKey(key string) string {
+	for _, k := range scriptInfoKeys {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return ""
+}
+
+// 设置已知键的值，数值无法解析时不做修改并返回错误
+func (si *ScriptInfo) set(key string, value string) error {
+	var err error
+	atoi := func(s string) int {
+		v, e := strconv.Atoi(s)
+		if e != nil {
+			err = fmt.Errorf("invalid value \"%s\" for %s: %w", s, key, ErrInvalidInfoValue)
+		}
+		return v
+	}
+	switch key {
+	case "Title":
+		si.Title = value
+	case "Original Script":
+		si.OriginalScript = value
+	case "ScriptType":
+		si.ScriptType = value
+	case "Collisions":
+		si.Collisions = value
+	case "PlayResX":
+		if v := atoi(value); err == nil {
+			si.PlayResX = v
+		}
+	case "PlayResY":
+		if v := atoi(value); err == nil {
+			si.PlayResY = v
+		}
+	case "LayoutResX":
+		if v := atoi(value); err == nil {
+			si.LayoutResX = v
+		}
+	case "LayoutResY":
+		if v := atoi(value); err == nil {
+			si.LayoutResY = v
+		}
+	case "Timer":
+		si.Timer = value
+	case "WrapStyle":
+		if v := atoi(value); err == nil {
+			si.WrapStyle = v
+		}
+	case "ScaledBorderAndShadow":
+		si.ScaledBorderAndShadow = strings.EqualFold(value, "yes") || value == "1"
+	case "YCbCr Matrix":
+		si.YCbCrMatrix = value
+	case "Kerning":
+		si.Kerning = strings.EqualFold(value, "yes") || value == "1"
+	}
+	return err
+}
+
+// 返回已知键的值，以及该值是否需要写出（已设置或原本存在）
+func (si *ScriptInfo) get(key string) (string, bool) {
+	itoa := func(v int) (string, bool) {
+		return strconv.Itoa(v), v != 0
+	}
+	yesNo := func(v bool) (string, bool) {
+		if v {
+			return "yes", true
+		}
+		return "no", false
+	}
+
+	var (
+		value string
+		isSet bool
+	)
+	switch key {
+	case "Title":
+		value, isSet = si.Title, si.Title != ""
+	case "Original Script":
+		value, isSet = si.OriginalScript, si.OriginalScript != ""
+	case "ScriptType":
+		value, isSet = si.ScriptType, si.ScriptType != ""
+	case "Collisions":
+		value, isSet = si.Collisions, si.Collisions != ""
+	case "PlayResX":
+		value, isSet = itoa(si.PlayResX)
+	case "PlayResY":
+		value, isSet = itoa(si.PlayResY)
+	case "LayoutResX":
+		value, isSet = itoa(si.LayoutResX)
+	case "LayoutResY":
+		value, isSet = itoa(si.LayoutResY)
+	case "Timer":
+		value, isSet = si.Timer, si.Timer != ""
+	case "WrapStyle":
+		value, isSet = itoa(si.WrapStyle)
+	case "ScaledBorderAndShadow":
+		value, isSet = yesNo(si.ScaledBorderAndShadow)
+	case "YCbCr Matrix":
+		value, isSet = si.YCbCrMatrix, si.YCbCrMatrix != ""
+	case "Kerning":
+		value, isSet = yesNo(si.Kerning)
+	}
+	raw, existed := si.raws[key]
+	if raw.invalid && !isSet { // 无法解析的值未被修改时保留原始值
+		return raw.value, true
+	}
+	return value, isSet || existed
+}
+
+// 按原始顺序序列化为多行文本，不包含区块标题
+// Others 依次填入原有的位置，多出的项与新设置的已知键追加在末尾
+// 重复的键只有最后一次出现的位置写入当前值，之前的位置写入原始文本
+// 原有的空行写回原位置，末尾的空行属于区块之间的分隔，不包含在内
+func (si *ScriptInfo) Lines() []string {
+	lines := make([]string, 0, len(si.order)+len(si.Others))
+	var pendingBlanks []string // 之后有内容时才写入的空行
+	appendLine := func(line string) {
+		lines = append(lines, pendingBlanks...)
+		lines = append(lines, line)
+		pendingBlanks = nil
+	}
+	writeKey := func(key string) {
+		value, ok := si.get(key)
+		if !ok {
+			return
+		}
+		if raw, ok := si.raws[key]; ok && raw.value == value {
+			appendLine(raw.line) // 未修改时保留原始文本
+			return
+		}
+		appendLine(key + ": " + value)
+	}
+
+	otherIdx, blankIdx := 0, 0
+	shadowedIdx := make(map[string]int)
+	for _, key := range si.order {
+		switch {
+		case key == blankEntry:
+			pendingBlanks = append(pendingBlanks, si.blanks[blankIdx])
+			blankIdx++
+		case key == "":
+			if otherIdx < len(si.Others) {
+				appendLine(si.Others[otherIdx].String())
+				otherIdx++
+			}
+		case shadowedIdx[key] < len(si.shadowed[key]):
+			appendLine(si.shadowed[key][shadowedIdx[key]])
+			shadowedIdx[key]++
+		default:
+			writeKey(key)
+		}
+	}
+	pendingBlanks = nil // 追加的内容写在末尾的空行之前
+	for ; otherIdx < len(si.Others); otherIdx++ {
+		appendLine(si.Others[otherIdx].String())
+	}
+	for _, key := range scriptInfoKeys {
+		if !slices.Contains(si.order, key) {
+			writeKey(key)
+		}
+	}
+	return lines
+}
+
+// 返回渲染器实际使用的脚本分辨率
+// 与 libass 一致，未设置时根据另一边推算，均未设置时为 384x288
+func (si *ScriptInfo) PlayRes() (int, int) {
+	x, y := si.PlayResX, si.PlayResY
+	switch {
+	case x <= 0 && y <= 0:
+		return 384, 288
+	case x <= 0:
+		if y == 1024 {
+			return 1280, y
+		}
+		return y * 4 / 3, y
+	case y <= 0:
+		if x == 1280 {
+			return x, 1024
+		}
+		return x, x * 3 / 4
+	}
+	return x, y
+}
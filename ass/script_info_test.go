@@ -0,0 +1,148 @@
+package ass_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const scriptInfoContent = `[Script Info]
+; Script generated by Aegisub 3.3.3
+; http://www.aegisub.org/
+Comment: Processed by 繁化姬 dict-f5430bf3-r1054 @ 2025/03/07 11:03:59 | https://zhconvert.org
+Title: Default Aegisub file
+ScriptType: v4.00+
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+YCbCr Matrix: TV.709
+PlayResX: 1920
+PlayResY: 1080
+`
+
+func TestScriptInfo(t *testing.T) {
+	content := strings.Replace(parseASSContent, "[Script Info]\nScriptType: v4.00+\n", scriptInfoContent, 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	si := ap.ScriptInfo
+	require.Equal(t, "Default Aegisub file", si.Title)
+	require.Equal(t, "v4.00+", si.ScriptType)
+	require.Equal(t, 0, si.WrapStyle)
+	require.True(t, si.ScaledBorderAndShadow)
+	require.Equal(t, "TV.709", si.YCbCrMatrix)
+	require.Equal(t, 1920, si.PlayResX)
+	require.Equal(t, 1080, si.PlayResY)
+	require.Len(t, si.Others, 3)
+	require.Equal(t, "Comment", si.Others[2].Key)
+
+	// 未修改时按原顺序输出原始内容
+	expect := []string{
+		"; Script generated by Aegisub 3.3.3",
+		"; http://www.aegisub.org/",
+		"Comment: Processed by 繁化姬 dict-f5430bf3-r1054 @ 2025/03/07 11:03:59 | https://zhconvert.org",
+		"Title: Default Aegisub file",
+		"ScriptType: v4.00+",
+		"WrapStyle: 0",
+		"ScaledBorderAndShadow: yes",
+		"YCbCr Matrix: TV.709",
+		"PlayResX: 1920",
+		"PlayResY: 1080",
+	}
+	require.Equal(t, expect, si.Lines())
+
+	si.PlayResX, si.PlayResY = 1280, 720
+	si.LayoutResX, si.LayoutResY = 1920, 1080
+	si.Others[2].Value = "resample"
+	si.Others = append(si.Others, ass.ScriptInfoEntry{Key: "Update Details", Value: "resample"})
+	expect = append(expect[:2], "Comment: resample")
+	expect = append(expect, "Title: Default Aegisub file", "ScriptType: v4.00+", "WrapStyle: 0", "ScaledBorderAndShadow: yes", "YCbCr Matrix: TV.709")
+	expect = append(expect, "PlayResX: 1280", "PlayResY: 720", "Update Details: resample", "LayoutResX: 1920", "LayoutResY: 1080")
+	require.Equal(t, expect, si.Lines())
+}
+
+func TestScriptInfoPlayRes(t *testing.T) {
+	testCases := []struct {
+		content string
+		x, y    int
+	}{
+		{content: "", x: 384, y: 288},
+		{content: "PlayResY: 1024", x: 1280, y: 1024},
+		{content: "PlayResY: 720", x: 960, y: 720},
+		{content: "PlayResX: 1280", x: 1280, y: 1024},
+		{content: "PlayResX: 1920\nPlayResY: 1080", x: 1920, y: 1080},
+	}
+
+	for _, tc := range testCases {
+		content := strings.Replace(parseASSContent, "ScriptType: v4.00+", tc.content, 1)
+		ap, err := ass.NewASSParser(strings.NewReader(content))
+		require.NoError(t, err)
+		require.NoError(t, ap.Parse())
+		x, y := ap.ScriptInfo.PlayRes()
+		require.Equal(t, tc.x, x, tc.content)
+		require.Equal(t, tc.y, y, tc.content)
+	}
+}
+
+func TestScriptInfoInvalidValue(t *testing.T) {
+	content := strings.Replace(parseASSContent, "ScriptType: v4.00+", "PlayResX: abc\nPlayResY: 720", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	si := ap.ScriptInfo
+	require.Equal(t, 0, si.PlayResX)
+	require.Equal(t, 720, si.PlayResY)
+	require.NotEmpty(t, ap.Diagnostics)
+	require.Equal(t, ass.SeverityWarning, ap.Diagnostics[0].Severity)
+	require.Equal(t, ass.CodeInvalidScriptInfo, ap.Diagnostics[0].Code)
+	require.Equal(t, uint(2), ap.Diagnostics[0].LineNum)
+	require.Equal(t, uint(11), ap.Diagnostics[0].Column)
+
+	// 未修改时保留原始值，不会写回 0
+	require.Equal(t, []string{"PlayResX: abc", "PlayResY: 720"}, si.Lines())
+
+	si.PlayResX = 1280
+	require.Equal(t, []string{"PlayResX: 1280", "PlayResY: 720"}, si.Lines())
+}
+
+func TestScriptInfoDuplicateKey(t *testing.T) {
+	content := strings.Replace(parseASSContent, "ScriptType: v4.00+", "PlayResX: 640\nScriptType: v4.00+\nplayresx:1920", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	// 以最后一次出现的值为准，未修改时原样写入
+	si := ap.ScriptInfo
+	require.Equal(t, 1920, si.PlayResX)
+	require.Equal(t, []string{"PlayResX: 640", "ScriptType: v4.00+", "playresx:1920"}, si.Lines())
+	var buf strings.Builder
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, content, buf.String())
+
+	// 修改后写入最后一次出现的位置
+	si.PlayResX = 1280
+	require.Equal(t, []string{"PlayResX: 640", "ScriptType: v4.00+", "PlayResX: 1280"}, si.Lines())
+}
+
+func TestScriptInfoBlankLine(t *testing.T) {
+	content := strings.Replace(parseASSContent, "ScriptType: v4.00+\n", "Title: a\n\nScriptType: v4.00+\nPlayResX: 640\n", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	// 区块中间的空行保留在原位置，未修改时原样写入
+	si := ap.ScriptInfo
+	require.Equal(t, []string{"Title: a", "", "ScriptType: v4.00+", "PlayResX: 640"}, si.Lines())
+	var buf strings.Builder
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, content, buf.String())
+
+	// 新设置的键写在区块末尾的空行之前
+	si.PlayResY = 480
+	buf.Reset()
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, strings.Replace(content, "PlayResX: 640\n", "PlayResX: 640\nPlayResY: 480\n", 1), buf.String())
+}
@@ -0,0 +1,206 @@
+package ass
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 返回事件所属部分的名称
+type SplitFunc func(di *DialogueInfo) (string, error)
+
+// 拆分得到的一部分字幕
+type SplitPart struct {
+	Key    string     // 部分的名称，即 SplitFunc 的返回值
+	Script *ASSParser // 只包含该部分的事件及其用到的样式
+}
+
+// 按样式名匹配的第一个模式拆分，模式的语法与 path.Match 相同，名称为模式本身
+// 不匹配任何模式的事件名称为空字符串
+func SplitByStylePattern(patterns ...string) SplitFunc {
+	return func(di *DialogueInfo) (string, error) {
+		for _, pattern := range patterns {
+			matched, err := path.Match(pattern, eventStyle(di))
+			if err != nil {
+				return "", fmt.Errorf("invalid style pattern \"%s\": %w", pattern, err)
+			}
+			if matched {
+				return pattern, nil
+			}
+		}
+		return "", nil
+	}
+}
+
+// 按 Name（说话人）字段拆分
+func SplitByActor() SplitFunc {
+	return func(di *DialogueInfo) (string, error) {
+		return di.Fields["Name"], nil
+	}
+}
+
+// 按 Layer 字段拆分
+func SplitByLayer() SplitFunc {
+	return func(di *DialogueInfo) (string, error) {
+		return strings.TrimSpace(di.Fields["Layer"]), nil
+	}
+}
+
+// 按开始时间以 points 为分界拆分，名称为从 1 开始的序号
+// 开始时间早于 points[0] 的事件属于 "1"，不小于 points[i] 且早于 points[i+1] 的事件属于 "i+2"
+// 跨越分界的事件只属于开始时间所在的部分
+func SplitByTime(points ...Timestamp) SplitFunc {
+	points = slices.Compact(slices.Sorted(slices.Values(points))) // 重复的分界只算一次
+	return func(di *DialogueInfo) (string, error) {
+		start, err := di.Start()
+		if err != nil {
+			return "", err
+		}
+		idx, found := slices.BinarySearch(points, start)
+		if found {
+			idx++
+		}
+		return strconv.Itoa(idx + 1), nil
+	}
+}
+
+// 按 fn 将事件拆分为多个字幕，部分按第一个事件出现的顺序排列，需要已调用过 Parse
+// 每个部分保留原有的脚本信息与其他区块，样式表只包含该部分的事件与 \r 引用的样式，
+// 并只根据该部分的事件统计 FontSets
+// 原有的嵌入字体与图片默认丢弃，使用 WithKeepEmbedded 时保留
+// 字幕本身不会被修改
+func (ap *ASSParser) Split(fn SplitFunc, opts ...SplitOption) ([]SplitPart, error) {
+	if !ap.parsed {
+		return nil, fmt.Errorf("failed to split ass content: %w", ErrNotParsed)
+	}
+	c := &splitConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	parts := make([]SplitPart, 0)
+	index := make(map[string]int) // 名称->在 parts 中的位置
+	events := make([][]*DialogueInfo, 0)
+	for _, di := range ap.EventTable.rows {
+		key, err := fn(di)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split event at line %d: %w", di.LineNum(), err)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(parts)
+			index[key] = i
+			parts = append(parts, SplitPart{Key: key})
+			events = append(events, make([]*DialogueInfo, 0))
+		}
+		events[i] = append(events[i], di)
+	}
+
+	for i := range parts {
+		script := ap.cloneEmpty()
+		if !c.keepEmbedded {
+			script.fontsContents, script.graphicsContents = nil, nil
+		}
+		used := ap.StyleTable.usedStyles(events[i], ap.config.fallback)
+		for _, si := range ap.StyleTable.rows {
+			if _, ok := used[si.Name()]; ok {
+				script.StyleTable.rows = append(script.StyleTable.rows, si.clone())
+			}
+		}
+		for _, di := range events[i] {
+			script.EventTable.rows = append(script.EventTable.rows, di.clone())
+		}
+		script.CollectFontSets()
+		parts[i].Script = script
+	}
+	return parts, nil
+}
+
+// 复制除样式与事件外的内容，返回的字幕样式表与事件表为空
+func (ap *ASSParser) cloneEmpty() *ASSParser {
+	c := *ap
+	c.Contents = slices.Clone(ap.Contents)
+	c.fontsContents = slices.Clone(ap.fontsContents)
+	c.graphicsContents = slices.Clone(ap.graphicsContents)
+	c.ScriptInfo = ap.ScriptInfo.clone()
+	c.StyleTable = &StyleTable{
+		Format:            ap.StyleTable.Format,
+		rows:              make([]*StyleInfo, 0),
+		styleNameFontDesc: maps.Clone(ap.StyleTable.styleNameFontDesc),
+	}
+	c.EventTable = &EventTable{Format: ap.EventTable.Format, rows: make([]*DialogueInfo, 0)}
+	c.FontSets = make(map[FontDesc]CodepointSet)
+	c.Diagnostics = make([]Diagnostic, 0)
+	return &c
+}
+
+// 返回事件的 Style 字段与 \r 标签引用的样式名
+// fallback 为 true 且存在未定义的样式时包括 Default
+func (st *StyleTable) usedStyles(events []*DialogueInfo, fallback bool) map[string]struct{} {
+	used := make(map[string]struct{})
+	for _, di := range events {
+		for _, name := range styleRefs(di) {
+			used[name] = struct{}{}
+			if fallback && st.Get(name) == nil {
+				used[defaultFontName] = struct{}{}
+			}
+		}
+	}
+	return used
+}
+
+// 事件引用的样式名，Style 字段为空时为 Default
+func styleRefs(di *DialogueInfo) []string {
+	refs := []string{eventStyle(di)}
+	text := di.Fields["Text"]
+	if !strings.Contains(text, `\r`) {
+		return refs
+	}
+	for _, seg := range tags.ParseText(text) {
+		if seg.Block == nil {
+			continue
+		}
+		seg.Block.Walk(func(tag *tags.Tag) bool {
+			if tag.Is("r") && tag.Arg() != "" {
+				refs = append(refs, tag.Arg())
+			}
+			return true
+		})
+	}
+	return refs
+}
+
+// 事件使用的样式名，Style 字段为空时为 Default
+func eventStyle(di *DialogueInfo) string {
+	if style := di.Fields["Style"]; style != "" {
+		return style
+	}
+	return defaultFontName
+}
+
+func (si *StyleInfo) clone() *StyleInfo {
+	c := *si
+	c.Fields = maps.Clone(si.Fields)
+	return &c
+}
+
+func (di *DialogueInfo) clone() *DialogueInfo {
+	c := *di
+	c.Fields = maps.Clone(di.Fields)
+	return &c
+}
+
+func (si *ScriptInfo) clone() *ScriptInfo {
+	c := *si
+	c.Others = slices.Clone(si.Others)
+	c.order = slices.Clone(si.order)
+	c.raws = maps.Clone(si.raws)
+	c.shadowed = maps.Clone(si.shadowed)
+	c.blanks = slices.Clone(si.blanks)
+	return &c
+}
@@ -0,0 +1,168 @@
+package ass_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+const splitASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: OP-JP,宋体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: OP-CN,仿宋,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,甲,0,0,0,,对白
+Dialogue: 1,0:01:00.00,0:01:05.00,Sign,,0,0,0,,招牌{\rDefault}说明
+Dialogue: 0,0:02:00.00,0:02:05.00,OP-JP,乙,0,0,0,,歌
+Dialogue: 2,0:02:00.00,0:02:05.00,OP-CN,乙,0,0,0,,词
+`
+
+func TestSplit(t *testing.T) {
+	type part struct {
+		key    string
+		styles []string
+		events int
+		fonts  []string
+	}
+	testCases := []struct {
+		name   string
+		fn     ass.SplitFunc
+		expect []part
+	}{
+		{
+			name: "按样式名",
+			fn:   ass.SplitByStylePattern("OP-*", "Sign"),
+			expect: []part{
+				{key: "", styles: []string{"Default"}, events: 1, fonts: []string{"楷体"}},
+				{key: "Sign", styles: []string{"Default", "Sign"}, events: 1, fonts: []string{"楷体", "黑体"}},
+				{key: "OP-*", styles: []string{"OP-JP", "OP-CN"}, events: 2, fonts: []string{"仿宋", "宋体"}},
+			},
+		},
+		{
+			name: "按说话人",
+			fn:   ass.SplitByActor(),
+			expect: []part{
+				{key: "甲", styles: []string{"Default"}, events: 1, fonts: []string{"楷体"}},
+				{key: "", styles: []string{"Default", "Sign"}, events: 1, fonts: []string{"楷体", "黑体"}},
+				{key: "乙", styles: []string{"OP-JP", "OP-CN"}, events: 2, fonts: []string{"仿宋", "宋体"}},
+			},
+		},
+		{
+			name: "按图层",
+			fn:   ass.SplitByLayer(),
+			expect: []part{
+				{key: "0", styles: []string{"Default", "OP-JP"}, events: 2, fonts: []string{"宋体", "楷体"}},
+				{key: "1", styles: []string{"Default", "Sign"}, events: 1, fonts: []string{"楷体", "黑体"}},
+				{key: "2", styles: []string{"OP-CN"}, events: 1, fonts: []string{"仿宋"}},
+			},
+		},
+		{
+			name: "按时间",
+			fn:   ass.SplitByTime(12000, 6000),
+			expect: []part{
+				{key: "1", styles: []string{"Default"}, events: 1, fonts: []string{"楷体"}},
+				{key: "2", styles: []string{"Default", "Sign"}, events: 1, fonts: []string{"楷体", "黑体"}},
+				{key: "3", styles: []string{"OP-JP", "OP-CN"}, events: 2, fonts: []string{"仿宋", "宋体"}},
+			},
+		},
+		{
+			name: "重复的分界",
+			fn:   ass.SplitByTime(6000, 12000, 6000),
+			expect: []part{
+				{key: "1", styles: []string{"Default"}, events: 1, fonts: []string{"楷体"}},
+				{key: "2", styles: []string{"Default", "Sign"}, events: 1, fonts: []string{"楷体", "黑体"}},
+				{key: "3", styles: []string{"OP-JP", "OP-CN"}, events: 2, fonts: []string{"仿宋", "宋体"}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ap, err := ass.NewASSParser(strings.NewReader(splitASSContent))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+
+			parts, err := ap.Split(tc.fn)
+			require.NoError(t, err)
+			require.Len(t, parts, len(tc.expect))
+			for i, p := range parts {
+				require.Equal(t, tc.expect[i].key, p.Key)
+				styles := make([]string, 0)
+				for _, si := range p.Script.StyleTable.Styles() {
+					styles = append(styles, si.Name())
+				}
+				require.Equal(t, tc.expect[i].styles, styles)
+				require.Equal(t, tc.expect[i].events, p.Script.EventTable.Len())
+				fonts := make([]string, 0)
+				for fd := range p.Script.FontSets {
+					fonts = append(fonts, fd.FontName)
+				}
+				slices.Sort(fonts)
+				require.Equal(t, tc.expect[i].fonts, fonts)
+			}
+
+			// 原字幕不受影响
+			require.Equal(t, 4, ap.StyleTable.Len())
+			require.Equal(t, 4, ap.EventTable.Len())
+		})
+	}
+
+	ap, err := ass.NewASSParser(strings.NewReader(splitASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	parts, err := ap.Split(ass.SplitByStylePattern("Sign"))
+	require.NoError(t, err)
+	var b strings.Builder
+	require.NoError(t, parts[1].Script.Write(&b))
+	require.Equal(t, `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,黑体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 1,0:01:00.00,0:01:05.00,Sign,,0,0,0,,招牌{\rDefault}说明
+`, b.String())
+
+	_, err = ap.Split(ass.SplitByStylePattern("["))
+	require.Error(t, err)
+}
+
+func TestSplitEmbedded(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(graphicsASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	// 默认丢弃原有的嵌入字体与图片
+	parts, err := ap.Split(ass.SplitByActor())
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	fonts, err := parts[0].Script.EmbeddedFonts()
+	require.NoError(t, err)
+	require.Empty(t, fonts)
+	graphics, err := parts[0].Script.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Empty(t, graphics)
+
+	parts, err = ap.Split(ass.SplitByActor(), ass.WithKeepEmbedded())
+	require.NoError(t, err)
+	fonts, err = parts[0].Script.EmbeddedFonts()
+	require.NoError(t, err)
+	require.Equal(t, []ass.EmbeddedFile{{Name: "a.ttf", Data: []byte("aaa")}}, fonts)
+	graphics, err = parts[0].Script.EmbeddedGraphics()
+	require.NoError(t, err)
+	require.Len(t, graphics, 2)
+}
@@ -0,0 +1,205 @@
+package ass
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// 流式读取时每一行的类型
+type ItemKind uint8
+
+const (
+	ItemLine       ItemKind = iota // 其他内容，如空行、注释或未知区块中的行
+	ItemSection                    // 区块标题，如 [Events]
+	ItemScriptInfo                 // [Script Info] 中的一行
+	ItemFormat                     // 样式或事件的格式定义行
+	ItemStyle                      // 样式行
+	ItemEvent                      // 事件行（Dialogue 或 Comment）
+	ItemFont                       // [Fonts] 中的一行
+	ItemGraphic                    // [Graphics] 中的一行
+)
+
+// 流式读取得到的一行内容
+type Item struct {
+	Kind    ItemKind      // 类型
+	Content *ContentInfo  // 原始内容
+	Style   *StyleInfo    // 样式，仅 ItemStyle 有效
+	Event   *DialogueInfo // 事件，仅 ItemEvent 有效
+}
+
+// 流式读取 ASS 字幕，逐行返回区块、样式与事件
+// 只保存脚本信息、样式表与嵌入区块，不保存其他原始内容与事件，适合体积很大的字幕
+type Reader struct {
+	ap          *ASSParser    // 保存脚本信息、样式表、嵌入区块、诊断信息与解析配置
+	src         io.Reader     // 原始输入
+	start       int64         // 输入实现 io.Seeker 时，创建 Reader 时的读取位置
+	br          *bufio.Reader // 已解码为 UTF-8 的输入
+	lineNum     uint          // 已读取的行数
+	state       parseState    // 当前所在的区块
+	es          embeddedState // 当前所在的嵌入区块
+	hasGraphics bool          // 已读取的内容中是否有 [Graphics] 区块
+}
+
+func NewReader(reader io.Reader, opts ...ParserOption) (*Reader, error) {
+	ap := &ASSParser{
+		ScriptInfo:  NewScriptInfo(),
+		StyleTable:  NewStyleTable(make(map[string]FontDesc)),
+		EventTable:  NewEventTable(),
+		FontSets:    make(map[FontDesc]CodepointSet),
+		Diagnostics: make([]Diagnostic, 0),
+	}
+	for _, opt := range opts {
+		opt(&ap.config)
+	}
+
+	var start int64
+	if seeker, ok := reader.(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to new Reader: %w", err)
+		}
+		start = offset
+	}
+	decoded, enc, bom, err := decodeReader(reader, ap.config.encoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new Reader: %w", err)
+	}
+	ap.encoding, ap.bom = enc, bom
+	return &Reader{ap: ap, src: reader, start: start, br: bufio.NewReader(decoded)}, nil
+}
+
+// 读取下一行，没有更多内容时返回 io.EOF
+// 样式行会同时加入 StyleTable，事件行不会被保存
+func (r *Reader) Next() (*Item, error) {
+	ci, err := r.ap.readLine(r.br, r.lineNum+1)
+	if err != nil {
+		return nil, err
+	}
+	r.lineNum++
+
+	item := &Item{Content: &ci}
+	r.hasGraphics = r.hasGraphics || isHeader(ci, "[graphics]")
+	switch r.es.section(ci) {
+	case "[fonts]":
+		r.ap.fontsContents = append(r.ap.fontsContents, ci)
+		item.Kind = ItemFont
+		return item, nil
+	case "[graphics]":
+		r.ap.graphicsContents = append(r.ap.graphicsContents, ci)
+		item.Kind = ItemGraphic
+		return item, nil
+	}
+	if r.state, err = r.ap.parseItem(item, r.state); err != nil {
+		return nil, fmt.Errorf("failed to parse ass content at line %d: %w", r.lineNum, err)
+	}
+	return item, nil
+}
+
+// 已读取的脚本信息
+func (r *Reader) ScriptInfo() *ScriptInfo {
+	return r.ap.ScriptInfo
+}
+
+// 已读取的样式表
+func (r *Reader) StyleTable() *StyleTable {
+	return r.ap.StyleTable
+}
+
+// 读取时使用的编码
+func (r *Reader) Encoding() TextEncoding {
+	return r.ap.encoding
+}
+
+// 流式统计字幕中所有事件用到的字符
+// 返回的 ASSParser 只包含脚本信息、样式表、字体集与诊断信息，可直接用于字体子集化
+func StreamFontSets(reader io.Reader, opts ...ParserOption) (*ASSParser, error) {
+	r, err := NewReader(reader, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		item, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item.Kind == ItemEvent {
+			r.ap.collectEvent(item.Event)
+		}
+	}
+
+	// 验证必要区块
+	if !r.state.hasStyle {
+		return nil, ErrStyleParseFailed
+	}
+	if !r.state.hasEvent {
+		return nil, ErrEventParseFailed
+	}
+	r.ap.cleanFontSets()
+	return r.ap, r.ap.checkStrict()
+}
+
+// 将字幕内容写入 writer，并写入嵌入字体的 [Fonts] 区块，写入的内容与 ASSParser.WriteWithEmbeddedFonts 相同
+// 先读取剩余的内容以获得全部嵌入区块，再回到创建 Reader 时的位置重新读取并写入，因此输入必须实现 io.Seeker
+// 原有的嵌入字体按 WithFontMergePolicy 指定的策略处理，默认全部丢弃
+func (r *Reader) WriteWithEmbeddedFonts(fontDatas map[string][]byte, writer io.Writer, opts ...WriteOption) error {
+	c := newWriteConfig(opts)
+	enc := c.encoding
+	if enc == EncodingAuto {
+		enc = r.ap.encoding
+	}
+
+	next, err := r.rewind()
+	if err != nil {
+		return fmt.Errorf("embed ass error when write to writer: %w", err)
+	}
+	err = r.ap.write(writer, enc, next, r.hasGraphics, func(lw *lineWriter, header ContentInfo) error {
+		return r.ap.writeFonts(lw, header, fontDatas, c)
+	})
+	if err != nil {
+		return fmt.Errorf("embed ass error when write to writer: %w", err)
+	}
+	return nil
+}
+
+// 读取剩余的内容，然后回到创建 Reader 时的位置
+// 返回依次读取不包括嵌入区块内容的原始行的函数，没有更多内容时返回 io.EOF
+func (r *Reader) rewind() (func() (ContentInfo, error), error) {
+	seeker, ok := r.src.(io.Seeker)
+	if !ok {
+		return nil, ErrNotSeekable
+	}
+	for {
+		if _, err := r.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := seeker.Seek(r.start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	decoded, _, _, err := decodeReader(r.src, r.ap.encoding)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(decoded)
+	var es embeddedState
+	var lineNum uint
+	return func() (ContentInfo, error) {
+		for {
+			ci, err := r.ap.readLine(br, lineNum+1)
+			if err != nil {
+				return ci, err
+			}
+			lineNum++
+			if es.section(ci) == "" { // 嵌入区块的内容已在第一次读取时保存
+				return ci, nil
+			}
+		}
+	}, nil
+}
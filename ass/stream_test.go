@@ -0,0 +1,111 @@
+package ass_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader(t *testing.T) {
+	r, err := ass.NewReader(strings.NewReader(parseASSContent))
+	require.NoError(t, err)
+
+	kinds := make([]ass.ItemKind, 0)
+	events := make([]string, 0)
+	for {
+		item, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		kinds = append(kinds, item.Kind)
+		if item.Kind == ass.ItemEvent {
+			events = append(events, item.Event.Fields["Text"])
+		}
+	}
+	require.Equal(t, []ass.ItemKind{
+		ass.ItemSection, ass.ItemScriptInfo, ass.ItemScriptInfo,
+		ass.ItemSection, ass.ItemFormat, ass.ItemStyle, ass.ItemStyle, ass.ItemLine,
+		ass.ItemSection, ass.ItemFormat, ass.ItemEvent, ass.ItemEvent, ass.ItemEvent,
+	}, kinds)
+	require.Equal(t, []string{"注释", "你好", "丢失"}, events)
+	require.Equal(t, 2, r.StyleTable().Len())
+	require.Equal(t, "v4.00+", r.ScriptInfo().ScriptType)
+}
+
+func TestStreamFontSets(t *testing.T) {
+	data, err := os.ReadFile(embeddedASSPath)
+	require.NoError(t, err)
+
+	ap, err := ass.NewASSParser(bytes.NewReader(data), ass.WithRenderer(ass.RendererLibass))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	stream, err := ass.StreamFontSets(bytes.NewReader(data), ass.WithRenderer(ass.RendererLibass))
+	require.NoError(t, err)
+	require.Equal(t, ap.FontSets, stream.FontSets)
+	require.Equal(t, ap.Diagnostics, stream.Diagnostics)
+
+	_, err = ass.StreamFontSets(strings.NewReader(parseASSContent), ass.WithStrict())
+	require.Error(t, err)
+}
+
+func TestReaderWriteWithEmbeddedFonts(t *testing.T) {
+	data, err := os.ReadFile(embeddedASSPath)
+	require.NoError(t, err)
+	fontsBlock := "[Fonts]\nfontname: a.ttf\n97&B\n\nfontname: b.ttf\n97&C\n"
+	fontsAfterEvents := strings.Replace(fontsASSContent, fontsBlock+"\n", "", 1) + "\n" + fontsBlock
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "嵌入字体的字幕", content: string(data)},
+		{name: "[Fonts]位于[Events]之前", content: fontsASSContent},
+		{name: "[Fonts]位于[Events]之后", content: fontsAfterEvents},
+		{name: "[Fonts]与[Events]之间有[Graphics]", content: graphicsASSContent},
+		{name: "[Graphics]位于[Events]之后", content: graphicsAfterEventsASSContent},
+		{name: "BOM与CRLF", content: "\uFEFF" + strings.ReplaceAll(fontsAfterEvents, "\n", "\r\n")},
+		{name: "混合换行符", content: strings.Replace(parseASSContent, "\n", "\r\n", 3)},
+	}
+	fontDatas := map[string][]byte{"b.ttf": []byte("new"), "new.ttf": []byte("new font")}
+
+	for _, tc := range testCases {
+		for _, policy := range []ass.FontMergePolicy{ass.FontMergeDrop, ass.FontMergeKeep, ass.FontMergeReplace} {
+			t.Run(fmt.Sprintf("%s/%d", tc.name, policy), func(t *testing.T) {
+				ap, err := ass.NewASSParser(strings.NewReader(tc.content))
+				require.NoError(t, err)
+				var expect ass.EmbedReport
+				var buf bytes.Buffer
+				require.NoError(t, ap.WriteWithEmbeddedFonts(fontDatas, &buf, ass.WithFontMergePolicy(policy), ass.WithEmbedReport(&expect)))
+
+				// 与非流式写入的结果完全相同
+				r, err := ass.NewReader(strings.NewReader(tc.content))
+				require.NoError(t, err)
+				var report ass.EmbedReport
+				var streamBuf bytes.Buffer
+				require.NoError(t, r.WriteWithEmbeddedFonts(fontDatas, &streamBuf, ass.WithFontMergePolicy(policy), ass.WithEmbedReport(&report)))
+				require.Equal(t, expect, report)
+				require.Equal(t, buf.String(), streamBuf.String())
+				require.LessOrEqual(t, strings.Count(streamBuf.String(), "[Fonts]"), 1)
+			})
+		}
+	}
+
+	// 保留原有字体且没有新字体时原样写入
+	r, err := ass.NewReader(strings.NewReader(fontsASSContent))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteWithEmbeddedFonts(nil, &buf, ass.WithFontMergePolicy(ass.FontMergeKeep)))
+	require.Equal(t, fontsASSContent, buf.String())
+
+	// 输入必须实现 io.Seeker
+	r, err = ass.NewReader(struct{ io.Reader }{strings.NewReader(fontsASSContent)})
+	require.NoError(t, err)
+	require.ErrorIs(t, r.WriteWithEmbeddedFonts(nil, io.Discard), ass.ErrNotSeekable)
+}
@@ -0,0 +1,330 @@
+package ass
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 对齐方式，按小键盘布局编号（\an 的取值）
+type Alignment int
+
+const (
+	AlignBottomLeft   Alignment = 1
+	AlignBottomCenter Alignment = 2
+	AlignBottomRight  Alignment = 3
+	AlignMiddleLeft   Alignment = 4
+	AlignMiddleCenter Alignment = 5
+	AlignMiddleRight  Alignment = 6
+	AlignTopLeft      Alignment = 7
+	AlignTopCenter    Alignment = 8
+	AlignTopRight     Alignment = 9
+)
+
+// 将 [V4 Styles] 与 \a 使用的旧式对齐编号转换为小键盘布局编号
+// 旧式编号中 1-3 为底部，5-7 为顶部，9-11 为中部
+func AlignmentFromLegacy(legacy int) Alignment {
+	col := legacy & 3
+	if col == 0 {
+		col = 2 // 与 libass 一致，非法的列视为居中
+	}
+	switch {
+	case legacy&4 != 0: // 顶部
+		return Alignment(col + 6)
+	case legacy&8 != 0: // 中部
+		return Alignment(col + 3)
+	}
+	return Alignment(col)
+}
+
+// 转换为旧式对齐编号
+func (a Alignment) Legacy() int {
+	col := (int(a)-1)%3 + 1
+	switch (int(a) - 1) / 3 {
+	case 1: // 中部
+		return col + 8
+	case 2: // 顶部
+		return col + 4
+	}
+	return col
+}
+
+// 边框样式
+type BorderStyle int
+
+const (
+	BorderStyleOutline   BorderStyle = 1 // 描边与阴影
+	BorderStyleOpaqueBox BorderStyle = 3 // 不透明背景框
+)
+
+// [V4+ Styles] 的默认格式定义
+var DefaultStyleFormat = &FormatInfo{
+	Fields: []string{
+		"Name", "Fontname", "Fontsize", "PrimaryColour", "SecondaryColour", "OutlineColour", "BackColour",
+		"Bold", "Italic", "Underline", "StrikeOut", "ScaleX", "ScaleY", "Spacing", "Angle",
+		"BorderStyle", "Outline", "Shadow", "Alignment", "MarginL", "MarginR", "MarginV", "Encoding",
+	},
+}
+
+// 带类型的样式
+type Style struct {
+	Name            string      // 样式名
+	FontName        string      // 字体名称，可能带有表示竖排的前缀 @
+	FontSize        float64     // 字号
+	PrimaryColour   Color       // 主要颜色
+	SecondaryColour Color       // 次要颜色（卡拉 OK 未唱部分）
+	OutlineColour   Color       // 边框颜色，[V4 Styles] 中为 TertiaryColour
+	BackColour      Color       // 阴影颜色
+	Bold            int         // 粗体，0 为不加粗，-1 或 1 为加粗，其余值为字重
+	Italic          bool        // 斜体
+	Underline       bool        // 下划线
+	StrikeOut       bool        // 删除线
+	ScaleX          float64     // 横向缩放百分比
+	ScaleY          float64     // 纵向缩放百分比
+	Spacing         float64     // 字间距
+	Angle           float64     // 旋转角度
+	BorderStyle     BorderStyle // 边框样式
+	Outline         float64     // 边框宽度
+	Shadow          float64     // 阴影距离
+	Alignment       Alignment   // 对齐方式
+	MarginL         int         // 左边距
+	MarginR         int         // 右边距
+	MarginV         int         // 垂直边距
+	Encoding        int         // 字符集，如 1 为默认，134 为 GB2312
+
+	Others map[string]string // 未知字段，字段名->值的映射
+}
+
+// 创建与 Aegisub 默认样式相同的样式
+func NewStyle(name string) *Style {
+	return &Style{
+		Name:            name,
+		FontName:        "Arial",
+		FontSize:        48,
+		PrimaryColour:   Color{R: 0xFF, G: 0xFF, B: 0xFF},
+		SecondaryColour: Color{R: 0xFF},
+		ScaleX:          100,
+		ScaleY:          100,
+		BorderStyle:     BorderStyleOutline,
+		Outline:         2,
+		Shadow:          2,
+		Alignment:       AlignBottomCenter,
+		MarginL:         10,
+		MarginR:         10,
+		MarginV:         10,
+		Encoding:        1,
+		Others:          make(map[string]string),
+	}
+}
+
+// 按格式定义解析样式行
+func ParseStyle(line string, format *FormatInfo) (*Style, error) {
+	// Style: Default,方正准圆_GBK,48,&H00FFFFFF,&HF0000000,&H00665806,&H0058281B,0,0,0,0,100,100,1,0,1,2,0,2,30,30,10,1
+	fields, err := ParseDataLine(line, format)
+	if err != nil {
+		return nil, err
+	}
+	return newStyleFromFields(fields, format)
+}
+
+// 转换为带类型的样式
+func (si *StyleInfo) Style() (*Style, error) {
+	return newStyleFromFields(si.Fields, si.formatInfo)
+}
+
+// 是否为 [V4 Styles] 的格式定义
+func isLegacyStyleFormat(format *FormatInfo) bool {
+	return format != nil && slices.ContainsFunc(format.Fields, func(field string) bool {
+		return strings.EqualFold(field, "TertiaryColour")
+	})
+}
+
+func newStyleFromFields(fields map[string]string, format *FormatInfo) (*Style, error) {
+	s := NewStyle(fields["Name"]) // 先设置样式名，便于错误信息中引用
+	s.FontName = ""
+	legacy := isLegacyStyleFormat(format)
+
+	for name, value := range fields {
+		if err := s.setField(name, value, legacy); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Style) setField(name string, value string, legacy bool) error {
+	var err error
+	parseColor := func(c *Color) {
+		*c, err = ParseColor(value)
+	}
+	parseFloat := func(f *float64) {
+		*f, err = strconv.ParseFloat(value, 64)
+	}
+	parseInt := func(i *int) {
+		*i, err = strconv.Atoi(value)
+	}
+	parseBool := func(b *bool) {
+		var i int
+		i, err = strconv.Atoi(value)
+		*b = i != 0
+	}
+
+	switch strings.ToLower(name) {
+	case "name":
+		s.Name = value
+	case "fontname":
+		s.FontName = value
+	case "fontsize":
+		parseFloat(&s.FontSize)
+	case "primarycolour":
+		parseColor(&s.PrimaryColour)
+	case "secondarycolour":
+		parseColor(&s.SecondaryColour)
+	case "outlinecolour", "tertiarycolour":
+		parseColor(&s.OutlineColour)
+	case "backcolour":
+		parseColor(&s.BackColour)
+	case "bold":
+		parseInt(&s.Bold)
+	case "italic":
+		parseBool(&s.Italic)
+	case "underline":
+		parseBool(&s.Underline)
+	case "strikeout":
+		parseBool(&s.StrikeOut)
+	case "scalex":
+		parseFloat(&s.ScaleX)
+	case "scaley":
+		parseFloat(&s.ScaleY)
+	case "spacing":
+		parseFloat(&s.Spacing)
+	case "angle":
+		parseFloat(&s.Angle)
+	case "borderstyle":
+		var i int
+		parseInt(&i)
+		s.BorderStyle = BorderStyle(i)
+	case "outline":
+		parseFloat(&s.Outline)
+	case "shadow":
+		parseFloat(&s.Shadow)
+	case "alignment":
+		var i int
+		parseInt(&i)
+		if legacy {
+			s.Alignment = AlignmentFromLegacy(i)
+		} else {
+			s.Alignment = Alignment(i)
+		}
+	case "marginl":
+		parseInt(&s.MarginL)
+	case "marginr":
+		parseInt(&s.MarginR)
+	case "marginv":
+		parseInt(&s.MarginV)
+	case "encoding":
+		parseInt(&s.Encoding)
+	default:
+		s.Others[name] = value
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s=%q in style %q", ErrInvalidStyleValue, name, value, s.Name)
+	}
+	return nil
+}
+
+// 返回字段的文本值，未知且不在 Others 中的字段返回空字符串
+func (s *Style) field(name string, legacy bool) string {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	formatBool := func(b bool) string {
+		if b {
+			return "-1"
+		}
+		return "0"
+	}
+
+	switch strings.ToLower(name) {
+	case "name":
+		return s.Name
+	case "fontname":
+		return s.FontName
+	case "fontsize":
+		return formatFloat(s.FontSize)
+	case "primarycolour":
+		return s.PrimaryColour.String()
+	case "secondarycolour":
+		return s.SecondaryColour.String()
+	case "outlinecolour", "tertiarycolour":
+		return s.OutlineColour.String()
+	case "backcolour":
+		return s.BackColour.String()
+	case "bold":
+		return strconv.Itoa(s.Bold)
+	case "italic":
+		return formatBool(s.Italic)
+	case "underline":
+		return formatBool(s.Underline)
+	case "strikeout":
+		return formatBool(s.StrikeOut)
+	case "scalex":
+		return formatFloat(s.ScaleX)
+	case "scaley":
+		return formatFloat(s.ScaleY)
+	case "spacing":
+		return formatFloat(s.Spacing)
+	case "angle":
+		return formatFloat(s.Angle)
+	case "borderstyle":
+		return strconv.Itoa(int(s.BorderStyle))
+	case "outline":
+		return formatFloat(s.Outline)
+	case "shadow":
+		return formatFloat(s.Shadow)
+	case "alignment":
+		if legacy {
+			return strconv.Itoa(s.Alignment.Legacy())
+		}
+		return strconv.Itoa(int(s.Alignment))
+	case "marginl":
+		return strconv.Itoa(s.MarginL)
+	case "marginr":
+		return strconv.Itoa(s.MarginR)
+	case "marginv":
+		return strconv.Itoa(s.MarginV)
+	case "encoding":
+		return strconv.Itoa(s.Encoding)
+	}
+	return s.Others[name]
+}
+
+// 字段的原始文本 raw 解析后是否与格式化后的 value 相同
+func sameFieldValue(name string, raw string, value string, legacy bool) bool {
+	s := NewStyle("")
+	if err := s.setField(name, raw, legacy); err != nil {
+		return false
+	}
+	return s.field(name, legacy) == value
+}
+
+// 按格式定义转换为字段名->值的映射
+func (s *Style) Fields(format *FormatInfo) map[string]string {
+	legacy := isLegacyStyleFormat(format)
+	fields := make(map[string]string, len(format.Fields))
+	for _, name := range format.Fields {
+		fields[name] = s.field(name, legacy)
+	}
+	return fields
+}
+
+// 按格式定义的字段顺序序列化为样式行
+func (s *Style) Line(format *FormatInfo) string {
+	legacy := isLegacyStyleFormat(format)
+	values := make([]string, len(format.Fields))
+	for i, name := range format.Fields {
+		values[i] = s.field(name, legacy)
+	}
+	return "Style: " + strings.Join(values, ",")
+}
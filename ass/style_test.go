@@ -0,0 +1,78 @@
+package ass_test
+
+import (
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	testCases := []struct {
+		name   string
+		raw    string
+		expect ass.Color
+		err    bool
+	}{
+		{name: "样式颜色", raw: "&H80665806", expect: ass.Color{R: 0x06, G: 0x58, B: 0x66, A: 0x80}},
+		{name: "覆盖标签颜色", raw: "&HA0350D&", expect: ass.Color{R: 0x0D, G: 0x35, B: 0xA0}},
+		{name: "小写", raw: "&hffffff", expect: ass.Color{R: 0xFF, G: 0xFF, B: 0xFF}},
+		{name: "十进制", raw: "16777215", expect: ass.Color{R: 0xFF, G: 0xFF, B: 0xFF}},
+		{name: "负数十进制", raw: "-2147483640", expect: ass.Color{R: 0x08, A: 0x80}},
+		{name: "空值", raw: "", err: true},
+		{name: "非法字符", raw: "&HXYZ", err: true},
+		{name: "过长", raw: "&H0011223344", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := ass.ParseColor(tc.raw)
+			if tc.err {
+				require.ErrorIs(t, err, ass.ErrInvalidColor)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, c)
+		})
+	}
+
+	c := ass.Color{R: 0x06, G: 0x58, B: 0x66, A: 0x80}
+	require.Equal(t, "&H80665806", c.String())
+	require.Equal(t, "&H665806&", c.TagString())
+}
+
+func TestParseStyle(t *testing.T) {
+	line := "Style: Default,方正准圆_GBK,48.5,&H00FFFFFF,&HF0000000,&H00665806,&H0058281B,-1,0,0,0,100,100,1,0,1,2,0,8,30,30,10,134"
+	s, err := ass.ParseStyle(line, ass.DefaultStyleFormat)
+	require.NoError(t, err)
+	require.Equal(t, "Default", s.Name)
+	require.Equal(t, "方正准圆_GBK", s.FontName)
+	require.Equal(t, 48.5, s.FontSize)
+	require.Equal(t, ass.Color{R: 0x06, G: 0x58, B: 0x66}, s.OutlineColour)
+	require.Equal(t, ass.Color{A: 0xF0}, s.SecondaryColour)
+	require.Equal(t, -1, s.Bold)
+	require.False(t, s.Italic)
+	require.Equal(t, ass.BorderStyleOutline, s.BorderStyle)
+	require.Equal(t, ass.AlignTopCenter, s.Alignment)
+	require.Equal(t, 30, s.MarginL)
+	require.Equal(t, 134, s.Encoding)
+	require.Equal(t, line, s.Line(ass.DefaultStyleFormat))
+
+	// 按文件声明的字段顺序输出
+	format, err := ass.ParseFormat("Format: Name, Fontsize, Fontname, Alignment")
+	require.NoError(t, err)
+	require.Equal(t, "Style: Default,48.5,方正准圆_GBK,8", s.Line(format))
+
+	// [V4 Styles] 使用旧式对齐编号
+	format, err = ass.ParseFormat("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, TertiaryColour, BackColour, Bold, Italic, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, AlphaLevel, Encoding")
+	require.NoError(t, err)
+	legacy, err := ass.ParseStyle("Style: Default,Arial,20,16777215,65535,65535,-2147483640,-1,0,1,3,0,6,30,30,30,0,0", format)
+	require.NoError(t, err)
+	require.Equal(t, ass.AlignTopCenter, legacy.Alignment)
+	require.Equal(t, ass.Color{R: 0xFF, G: 0xFF}, legacy.OutlineColour)
+	require.Equal(t, "0", legacy.Others["AlphaLevel"])
+	require.Equal(t, "Style: Default,Arial,20,&H00FFFFFF,&H0000FFFF,&H0000FFFF,&H80000008,-1,0,1,3,0,6,30,30,30,0,0", legacy.Line(format))
+
+	_, err = ass.ParseStyle("Style: Default,Arial,abc", format)
+	require.ErrorIs(t, err, ass.ErrInvalidStyleValue)
+}
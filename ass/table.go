@@ -1,14 +1,20 @@
 package ass
 
-import "strings"
+import (
+	"maps"
+	"slices"
+	"strings"
+)
 
 // 样式表结构体
 type StyleTable struct {
 	Format            *FormatInfo         // 表头格式定义
 	rows              []*StyleInfo        // 数据行
-	styleNameFontDesc map[string]FontDesc // 样式名->字体信息
+	styleNameFontDesc map[string]FontDesc // 额外的样式名->字体信息，样式表中不存在同名样式时使用
 }
 
+// 创建样式表
+// styleNameFontDesc 为额外的样式名->字体信息映射，可为 nil
 func NewStyleTable(styleNameFontDesc map[string]FontDesc) *StyleTable {
 	s := StyleTable{
 		Format:            nil,
@@ -20,54 +26,283 @@ func NewStyleTable(styleNameFontDesc map[string]FontDesc) *StyleTable {
 
 // Append 添加样式到样式表
 func (st *StyleTable) Append(style *StyleInfo) {
-	// Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
-	// Style: Default,方正准圆_GBK,48,&H00FFFFFF,&HF0000000,&H00665806,&H0058281B,0,0,0,0,100,100,1,0,1,2,0,2,30,30,10,1
+	st.Insert(len(st.rows), style)
+}
+
+// 在指定位置插入样式，idx 超出范围时追加到末尾
+// 样式没有格式定义时使用样式表的格式定义
+func (st *StyleTable) Insert(idx int, style *StyleInfo) {
+	if style.formatInfo == nil {
+		style.formatInfo = st.format()
+	}
+	idx = max(0, min(idx, len(st.rows)))
+	st.rows = slices.Insert(st.rows, idx, style)
+}
+
+// 删除所有指定名称的样式，返回删除的数量
+func (st *StyleTable) Remove(name string) int {
+	n := len(st.rows)
+	st.rows = slices.DeleteFunc(st.rows, func(si *StyleInfo) bool {
+		return si.Name() == name
+	})
+	return n - len(st.rows)
+}
+
+// 样式数量
+func (st *StyleTable) Len() int {
+	return len(st.rows)
+}
+
+// 返回所有样式，修改返回的切片不会影响样式表
+func (st *StyleTable) Styles() []*StyleInfo {
+	return slices.Clone(st.rows)
+}
+
+// 根据样式名称获取样式，存在同名样式时与渲染器一致返回最后一个
+func (st *StyleTable) Get(name string) *StyleInfo {
+	for i := len(st.rows) - 1; i >= 0; i-- {
+		if st.rows[i].Name() == name {
+			return st.rows[i]
+		}
+	}
+	return nil
+}
+
+// 根据样式名称获取字体描述
+func (st *StyleTable) GetFontDescByName(name string) *FontDesc {
+	if si := st.Get(name); si != nil {
+		fd := si.fontDesc()
+		return &fd
+	}
+	if fd, ok := st.styleNameFontDesc[name]; ok {
+		return &fd
+	}
+	return nil
+}
+
+// 返回样式表的格式定义，未设置时使用默认格式
+func (st *StyleTable) format() *FormatInfo {
+	if st.Format != nil {
+		return st.Format
+	}
+	return DefaultStyleFormat
+}
 
-	styleName, ok := style.Fields["Name"]
-	if !ok || styleName == "" {
-		styleName = defaultFontName
+// 对话事件表结构体
+type EventTable struct {
+	Format *FormatInfo     // 表头格式定义
+	rows   []*DialogueInfo // 数据行
+}
+
+// 创建事件表
+func NewEventTable() *EventTable {
+	return &EventTable{
+		Format: nil,
+		rows:   make([]*DialogueInfo, 0),
+	}
+}
+
+// 添加事件到事件表末尾
+func (et *EventTable) Append(event *DialogueInfo) {
+	et.Insert(len(et.rows), event)
+}
+
+// 在指定位置插入事件，idx 超出范围时追加到末尾
+// 事件没有格式定义时使用事件表的格式定义
+func (et *EventTable) Insert(idx int, event *DialogueInfo) {
+	if event.formatInfo == nil {
+		event.formatInfo = et.format()
+	}
+	idx = max(0, min(idx, len(et.rows)))
+	et.rows = slices.Insert(et.rows, idx, event)
+}
+
+// 删除指定位置的事件
+func (et *EventTable) Delete(idx int) {
+	if idx < 0 || idx >= len(et.rows) {
+		return
 	}
+	et.rows = slices.Delete(et.rows, idx, idx+1)
+}
+
+// 删除所有满足条件的事件，返回删除的数量
+func (et *EventTable) DeleteFunc(del func(*DialogueInfo) bool) int {
+	n := len(et.rows)
+	et.rows = slices.DeleteFunc(et.rows, del)
+	return n - len(et.rows)
+}
+
+// 事件数量
+func (et *EventTable) Len() int {
+	return len(et.rows)
+}
+
+// 返回所有事件，修改返回的切片不会影响事件表
+func (et *EventTable) Events() []*DialogueInfo {
+	return slices.Clone(et.rows)
+}
 
-	fontname, ok := style.Fields["Fontname"]
-	if !ok {
-		fontname = ""
+// 返回事件表的格式定义，未设置时使用默认格式
+func (et *EventTable) format() *FormatInfo {
+	if et.Format != nil {
+		return et.Format
 	}
-	fontname = strings.TrimPrefix(fontname, "@") // 去掉前缀 @（如果有的话）
+	return DefaultEventFormat
+}
+
+// [Events] 的默认格式定义
+var DefaultEventFormat = &FormatInfo{
+	Fields: []string{"Layer", "Start", "End", "Style", "Name", "MarginL", "MarginR", "MarginV", "Effect", "Text"},
+}
 
+// 根据带类型的样式创建样式行
+// format 为 nil 时在插入样式表时使用样式表的格式定义
+func NewStyleInfo(style *Style, format *FormatInfo) *StyleInfo {
+	si := &StyleInfo{formatInfo: format}
+	si.SetStyle(style)
+	return si
+}
+
+// 样式名，为空时与渲染器一致视为 Default
+func (si *StyleInfo) Name() string {
+	if name := si.Fields["Name"]; name != "" {
+		return name
+	}
+	return defaultFontName
+}
+
+// 样式在文件中的行号，不是从文件中读取的样式返回 0
+func (si *StyleInfo) LineNum() uint {
+	return lineNum(si.content)
+}
+
+// 字段在原始行中的起始列号（按字符计，从 1 开始），未知时返回 0
+func (si *StyleInfo) FieldColumn(field string) uint {
+	return contentFieldColumn(si.content, si.formatInfo, field)
+}
+
+// 使用带类型的样式覆盖全部字段
+// 值未改变的字段保留原有的写法，如布尔值的 1 与 -1
+func (si *StyleInfo) SetStyle(style *Style) {
+	format := si.formatInfo
+	if format == nil {
+		format = DefaultStyleFormat
+	}
+	legacy := isLegacyStyleFormat(format)
+	fields := style.Fields(format)
+	for name, value := range fields {
+		if raw, ok := si.Fields[name]; ok && raw != value && sameFieldValue(name, raw, value, legacy) {
+			fields[name] = raw
+		}
+	}
+	si.Fields = fields
+}
+
+// 计算样式对应的字体描述
+func (si *StyleInfo) fontDesc() FontDesc {
+	fontName, vertical := splitVerticalFontName(si.Fields["Fontname"])
 	fd := FontDesc{
-		FontName: fontname,
+		FontName: fontName,        // 去掉前缀 @（如果有的话）
 		Bold:     defaultFontSize, // 默认粗细大小
 		Italic:   defaultItalic,   // 默认不斜体
+		Vertical: vertical,        // 前缀 @ 表示竖排
 	}
 
-	if boldStr, ok := style.Fields["Bold"]; ok {
+	if boldStr, ok := si.Fields["Bold"]; ok {
 		if bold, err := calculateBold(boldStr); err == nil || err == ErrInvalidBoldValue {
 			fd.Bold = bold // 计算粗体大小
 		}
 	}
 
-	if italicStr, ok := style.Fields["Italic"]; ok {
+	if italicStr, ok := si.Fields["Italic"]; ok {
 		if italic, err := calculateItalic(italicStr); err == nil || err == ErrInvalidItalicValue {
 			fd.Italic = italic // 是否启用斜体
 		}
 	}
 
-	st.rows = append(st.rows, style)     // 添加样式到样式表
-	st.styleNameFontDesc[styleName] = fd // 保存样式名称对应的字体描述
+	if charsetStr, ok := si.Fields["Encoding"]; ok {
+		if charset, err := calculateCharset(charsetStr); err == nil {
+			fd.Charset = charset // 字符集
+		}
+	}
+	return fd
 }
 
-// 根据样式名称获取样式信息
-func (st *StyleTable) GetFontDescByName(name string) *FontDesc {
-	for styleName, fd := range st.styleNameFontDesc {
-		if styleName == name {
-			return &fd
-		}
+// 按格式定义序列化为样式行，未修改的样式返回原始文本
+func (si *StyleInfo) line(format *FormatInfo) string {
+	if raw, ok := unchangedRaw(si.content, si.formatInfo, format, si.Fields); ok {
+		return raw
 	}
-	return nil
+	return "Style: " + joinFields(si.Fields, format)
 }
 
-// 对话事件表结构体
-type EventTable struct {
-	Format *FormatInfo     // 表头格式定义
-	rows   []*DialogueInfo // 数据行
+// 根据字段创建对话行
+// format 为 nil 时在插入事件表时使用事件表的格式定义
+func NewDialogueInfo(fields map[string]string, format *FormatInfo) *DialogueInfo {
+	return &DialogueInfo{
+		formatInfo: format,
+		Fields:     fields,
+	}
+}
+
+// 设置是否为 Comment 行
+func (di *DialogueInfo) SetComment(comment bool) {
+	di.comment = comment
+}
+
+// 事件在文件中的行号，不是从文件中读取的事件返回 0
+func (di *DialogueInfo) LineNum() uint {
+	return lineNum(di.content)
+}
+
+// 字段在原始行中的起始列号（按字符计，从 1 开始），未知时返回 0
+func (di *DialogueInfo) FieldColumn(field string) uint {
+	return contentFieldColumn(di.content, di.formatInfo, field)
+}
+
+// 按格式定义序列化为事件行，未修改的事件返回原始文本
+func (di *DialogueInfo) line(format *FormatInfo) string {
+	prefix := "Dialogue: "
+	if di.comment {
+		prefix = "Comment: "
+	}
+	if raw, ok := unchangedRaw(di.content, di.formatInfo, format, di.Fields); ok && startWith(raw, prefix) {
+		return raw
+	}
+	return prefix + joinFields(di.Fields, format)
+}
+
+func lineNum(content *ContentInfo) uint {
+	if content == nil {
+		return 0
+	}
+	return content.LineNum
+}
+
+func contentFieldColumn(content *ContentInfo, format *FormatInfo, field string) uint {
+	if content == nil {
+		return 0
+	}
+	return fieldColumn(content.RawContent, format, field)
+}
+
+// 数据行未被修改时返回原始文本
+func unchangedRaw(content *ContentInfo, rowFormat *FormatInfo, format *FormatInfo, fields map[string]string) (string, bool) {
+	if content == nil || rowFormat != format {
+		return "", false
+	}
+	origin, err := ParseDataLine(content.RawContent, format)
+	if err != nil || !maps.Equal(origin, fields) {
+		return "", false
+	}
+	return content.RawContent, true
+}
+
+// 按格式定义的顺序拼接字段值
+func joinFields(fields map[string]string, format *FormatInfo) string {
+	values := make([]string, len(format.Fields))
+	for i, name := range format.Fields {
+		values[i] = fields[name]
+	}
+	return strings.Join(values, ",")
 }
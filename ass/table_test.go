@@ -0,0 +1,157 @@
+package ass_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteUnchanged(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(parseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	var buf bytes.Buffer
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, parseASSContent, buf.String())
+}
+
+// 未修改的字幕写出后应与原始文件完全一致，包括 BOM、换行符与已嵌入的字体
+func TestWriteRoundTrip(t *testing.T) {
+	files, err := filepath.Glob("../test_case/ass/*.ass*")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			ap, err := ass.NewASSParser(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.NoError(t, ap.Parse())
+
+			var buf bytes.Buffer
+			require.NoError(t, ap.Write(&buf))
+			require.True(t, bytes.Equal(data, buf.Bytes()))
+		})
+	}
+
+	// 最后一行没有换行符
+	content := strings.TrimSuffix(strings.ReplaceAll(parseASSContent, "\n", "\r\n"), "\r\n")
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	var buf bytes.Buffer
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, content, buf.String())
+
+	// 混合换行符，未修改的行保留各自的换行符，修改的行使用第一个换行符
+	lines := strings.SplitAfter(parseASSContent, "\n")
+	for i := 1; i < len(lines); i += 2 {
+		lines[i] = strings.Replace(lines[i], "\n", "\r\n", 1)
+	}
+	content = strings.Join(lines, "")
+	ap, err = ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	buf.Reset()
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, content, buf.String())
+
+	ap.EventTable.Events()[1].Fields["Text"] = "再见"
+	buf.Reset()
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, strings.Replace(content, "你好\r\n", "再见\n", 1), buf.String())
+}
+
+func TestSetStyleKeepsRepresentation(t *testing.T) {
+	content := strings.Replace(parseASSContent, "&H80000000,1,0,0,0,100,100,", "&h80000000,1,1,0,0,100.0,100,", 1)
+	ap, err := ass.NewASSParser(strings.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	// 只有修改的字段重新格式化，其余字段保留原有的写法
+	title := ap.StyleTable.Get("Title")
+	style, err := title.Style()
+	require.NoError(t, err)
+	style.FontSize = 30
+	title.SetStyle(style)
+	require.Equal(t, "1", title.Fields["Italic"])
+	require.Equal(t, "100.0", title.Fields["ScaleX"])
+	require.Equal(t, "&h80000000", title.Fields["BackColour"])
+	require.Equal(t, "30", title.Fields["Fontsize"])
+
+	style.Italic = false
+	title.SetStyle(style)
+	require.Equal(t, "0", title.Fields["Italic"])
+}
+
+func TestEditModel(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(parseASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+	require.Equal(t, 2, ap.StyleTable.Len())
+	require.Equal(t, 3, ap.EventTable.Len())
+
+	// 修改样式
+	title := ap.StyleTable.Get("Title")
+	require.NotNil(t, title)
+	style, err := title.Style()
+	require.NoError(t, err)
+	style.FontName = "黑体"
+	style.Bold = 0
+	title.SetStyle(style)
+
+	// 新增样式与事件，删除未定义样式的事件
+	missing := ass.NewStyle("Missing")
+	missing.FontName = "仿宋"
+	ap.StyleTable.Append(ass.NewStyleInfo(missing, nil))
+	require.Equal(t, 1, ap.StyleTable.Remove("Missing"))
+	ap.StyleTable.Insert(0, ass.NewStyleInfo(missing, nil))
+
+	events := ap.EventTable.Events()
+	events[0].SetComment(false)
+	events[1].Fields["Text"] = "你好，世界"
+	ap.EventTable.Delete(2)
+	ap.EventTable.Append(ass.NewDialogueInfo(map[string]string{
+		"Layer": "0",
+		"Start": "0:00:10.00",
+		"End":   "0:00:12.00",
+		"Style": "Missing",
+		"Text":  "新增",
+	}, nil))
+
+	ap.ScriptInfo.PlayResX = 1920
+
+	var buf bytes.Buffer
+	require.NoError(t, ap.Write(&buf))
+	require.Equal(t, `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Missing,仿宋,48,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,2,2,10,10,10,1
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Title,黑体,24,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:00.00,0:00:05.00,Title,,0,0,0,,注释
+Dialogue: 0,0:00:00.00,0:00:05.00,Default,,0,0,0,,你好，世界
+Dialogue: 0,0:00:10.00,0:00:12.00,Missing,,,,,,新增
+`, buf.String())
+
+	// 修改后重新统计字符
+	ap.CollectFontSets()
+	require.Equal(t, map[ass.FontDesc]ass.CodepointSet{
+		{FontName: "黑体", Bold: 400, Italic: 0}: {'注': {}, '释': {}},
+		{FontName: "楷体", Bold: 400, Italic: 0}: {'你': {}, '好': {}, '，': {}, '世': {}, '界': {}},
+		{FontName: "仿宋", Bold: 400, Italic: 0}: {'新': {}, '增': {}},
+	}, ap.FontSets)
+}
@@ -0,0 +1,172 @@
+package tags
+
+import (
+	"slices"
+	"strings"
+)
+
+// 解析样式覆盖段（不含花括号）
+func Parse(code string) *Block {
+	return parse(code, 0)
+}
+
+// offset 为 code 在最外层覆盖段中的字节偏移
+func parse(code string, offset int) *Block {
+	// \fad(500,0)\fnB3CJROEU\fs22\frz19.65\c&H6C6D6F&\pos(468,349)
+	b := &Block{Tags: make([]*Tag, 0)}
+	pos := 0
+	for pos < len(code) {
+		if code[pos] != '\\' { // 标签之外的文本
+			end := nextBackslash(code, pos)
+			b.Tags = append(b.Tags, &Tag{Kind: KindText, Offset: offset + pos, raw: code[pos:end]})
+			pos = end
+			continue
+		}
+		tag, end := parseTag(code, pos, offset)
+		b.Tags = append(b.Tags, tag)
+		pos = end
+	}
+	return b
+}
+
+// 解析从 pos 开始的标签，返回标签及下一个未处理字节的位置
+func parseTag(code string, pos int, offset int) (*Tag, int) {
+	tag := &Tag{Kind: KindUnknown, Offset: offset + pos}
+	p := pos + 1 // 跳过 '\'
+
+	for _, name := range sortedNames {
+		if strings.HasPrefix(code[p:], name) {
+			tag.Kind = KindTag
+			tag.Name = name
+			break
+		}
+	}
+	if tag.Kind == KindUnknown { // 未知标签取连续的字母作为标签名
+		end := p
+		for end < len(code) && isLetter(code[end]) {
+			end++
+		}
+		tag.Name = code[p:end]
+	}
+	p += len(tag.Name)
+
+	switch {
+	case slices.Contains(stringArgNames, tag.Name):
+		end := nextBackslash(code, p)
+		tag.Args = []string{strings.TrimSpace(code[p:end])}
+		p = end
+
+	case strings.HasPrefix(strings.TrimLeft(code[p:], " "), "("):
+		tag.Paren = true
+		p += strings.Index(code[p:], "(") + 1
+		argsStart := p
+		depth := 1
+		for p < len(code) && depth > 0 {
+			switch code[p] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case '\\':
+				if depth == 1 && tag.Name != "t" {
+					depth = -1 // 括号未闭合，参数到下一个标签为止
+					continue
+				}
+			}
+			if depth > 0 {
+				p++
+			}
+		}
+		argsEnd := p
+		if depth == 0 {
+			p++ // 跳过 ')'
+		} else {
+			tag.Malformed = true
+		}
+		tag.Args = splitArgs(code[argsStart:argsEnd])
+		if tag.Name == "t" && len(tag.Args) > 0 {
+			last := tag.Args[len(tag.Args)-1]
+			if strings.HasPrefix(last, "\\") {
+				nestedStart := argsStart + strings.LastIndex(code[argsStart:argsEnd], last)
+				tag.Nested = parse(last, offset+nestedStart)
+				tag.rawNested = last
+			}
+		}
+
+	default:
+		end := nextBackslash(code, p)
+		if arg := strings.TrimSpace(code[p:end]); arg != "" {
+			tag.Args = []string{arg}
+		}
+		p = end
+	}
+
+	tag.raw = code[pos:p]
+	tag.rawArgs = slices.Clone(tag.Args)
+	return tag, p
+}
+
+// 按最外层的逗号分割参数
+func splitArgs(s string) []string {
+	args := make([]string, 0)
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+// 返回从 pos 开始的第一个 '\' 的位置，没有时返回 len(code)
+func nextBackslash(code string, pos int) int {
+	if idx := strings.IndexByte(code[pos:], '\\'); idx >= 0 {
+		return pos + idx
+	}
+	return len(code)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// 将对话文本拆分为样式覆盖段与普通文本
+// 转义的花括号 \{ \} 以及未闭合的 '{' 均视为普通文本
+func ParseText(text string) []Segment {
+	segments := make([]Segment, 0)
+	start := 0 // 当前普通文本的起始位置
+	pos := 0
+	for pos < len(text) {
+		switch {
+		case text[pos] == '\\' && pos+1 < len(text) && (text[pos+1] == '{' || text[pos+1] == '}'):
+			pos += 2 // 跳过转义的花括号
+		case text[pos] == '{':
+			end := strings.IndexByte(text[pos+1:], '}')
+			if end < 0 { // 没有找到 '}'
+				pos = len(text)
+				continue
+			}
+			if start < pos {
+				segments = append(segments, Segment{Text: text[start:pos]})
+			}
+			segments = append(segments, Segment{Block: Parse(text[pos+1 : pos+1+end])})
+			pos += end + 2
+			start = pos
+		default:
+			pos++
+		}
+	}
+	if start < len(text) {
+		segments = append(segments, Segment{Text: text[start:]})
+	}
+	return segments
+}
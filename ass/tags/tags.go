@@ -0,0 +1,215 @@
+// Package tags 解析 ASS 对话文本中的样式覆盖标签
+//
+// 解析结果可以原样序列化回文本，修改后的标签会重新生成
+package tags
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Kind uint8
+
+const (
+	KindText    Kind = iota // 覆盖段中不属于任何标签的文本（如注释）
+	KindTag                 // 已知标签
+	KindUnknown             // 未知标签
+)
+
+// 已知的标签名
+// 参考 libass、VSFilter 及 VSFilterMod 支持的标签
+var knownNames = []string{
+	// 字体
+	"fn", "fs", "fscx", "fscy", "fsp", "fe", "fsc",
+	"b", "i", "u", "s",
+	// 边框、阴影与模糊
+	"bord", "xbord", "ybord", "shad", "xshad", "yshad", "be", "blur",
+	// 旋转与剪切
+	"fr", "frx", "fry", "frz", "fax", "fay",
+	// 颜色与透明度
+	"c", "1c", "2c", "3c", "4c", "alpha", "1a", "2a", "3a", "4a",
+	// 对齐与换行
+	"a", "an", "q",
+	// 卡拉 OK
+	"k", "K", "kf", "ko", "kt",
+	// 位置与动画
+	"pos", "move", "org", "fad", "fade", "t", "clip", "iclip",
+	// 绘图
+	"p", "pbo",
+	// 样式重置
+	"r",
+	// VSFilterMod
+	"rnd", "rndx", "rndy", "rndz",
+}
+
+// 参数为字符串，直到下一个 '\' 为止的标签（参数中可以包含括号）
+var stringArgNames = []string{"fn", "r"}
+
+// 按长度降序排列，保证优先匹配最长的标签名
+var sortedNames []string
+
+func init() {
+	sortedNames = slices.Clone(knownNames)
+	sort.SliceStable(sortedNames, func(i, j int) bool {
+		return len(sortedNames[i]) > len(sortedNames[j])
+	})
+}
+
+// 判断是否为已知的标签名
+func IsKnown(name string) bool {
+	return slices.Contains(knownNames, name)
+}
+
+// 样式覆盖段中的单个标签或文本
+type Tag struct {
+	Kind      Kind     // 类型
+	Name      string   // 标签名，不含前导 '\'，如 "fn"、"1c"、"pos"
+	Args      []string // 参数，已去除首尾空白
+	Paren     bool     // 参数是否由括号包裹
+	Nested    *Block   // \t 中内嵌的标签，为 nil 表示没有
+	Malformed bool     // 括号未闭合
+	Offset    int      // 在覆盖段中的字节偏移
+	raw       string   // 原始文本
+	rawArgs   []string // 解析时的参数，用于判断是否被修改
+	rawNested string   // 解析时内嵌标签的文本
+}
+
+// 创建新的标签
+func NewTag(name string, args ...string) *Tag {
+	kind := KindTag
+	if !IsKnown(name) {
+		kind = KindUnknown
+	}
+	return &Tag{
+		Kind:  kind,
+		Name:  name,
+		Args:  args,
+		Paren: len(args) > 1 || slices.Contains([]string{"pos", "move", "org", "fad", "fade", "t", "clip", "iclip"}, name),
+	}
+}
+
+// 创建覆盖段中的文本
+func NewText(text string) *Tag {
+	return &Tag{Kind: KindText, raw: text}
+}
+
+// 返回第一个参数，没有参数时返回空字符串
+func (t *Tag) Arg() string {
+	if len(t.Args) == 0 {
+		return ""
+	}
+	return t.Args[0]
+}
+
+// 是否为指定名称的标签
+func (t *Tag) Is(names ...string) bool {
+	return t.Kind != KindText && slices.Contains(names, t.Name)
+}
+
+// 序列化标签
+// 未被修改的标签返回原始文本
+func (t *Tag) String() string {
+	if t.Kind == KindText {
+		return t.raw
+	}
+	if t.raw != "" && !t.modified() {
+		return t.raw
+	}
+
+	args := t.Args
+	if t.Nested != nil { // 最后一个参数为内嵌标签
+		args = slices.Clone(args)
+		if len(args) == 0 {
+			args = append(args, "")
+		}
+		args[len(args)-1] = t.Nested.String()
+	}
+
+	var b strings.Builder
+	b.WriteString("\\" + t.Name)
+	if t.Paren {
+		b.WriteString("(" + strings.Join(args, ",") + ")")
+	} else {
+		b.WriteString(strings.Join(args, ","))
+	}
+	return b.String()
+}
+
+func (t *Tag) modified() bool {
+	if !slices.Equal(t.Args, t.rawArgs) {
+		return true
+	}
+	return t.Nested != nil && t.Nested.String() != t.rawNested
+}
+
+// 一个样式覆盖段（花括号之间的内容）
+type Block struct {
+	Tags []*Tag
+}
+
+// 序列化样式覆盖段，不包含花括号
+func (b *Block) String() string {
+	var sb strings.Builder
+	for _, tag := range b.Tags {
+		sb.WriteString(tag.String())
+	}
+	return sb.String()
+}
+
+// 依次遍历覆盖段中的标签，包括 \t 中内嵌的标签
+// fn 返回 false 时停止遍历
+func (b *Block) Walk(fn func(tag *Tag) bool) bool {
+	for _, tag := range b.Tags {
+		if tag.Kind == KindText {
+			continue
+		}
+		if !fn(tag) {
+			return false
+		}
+		if tag.Nested != nil && !tag.Nested.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// 查找最后一个指定名称的标签（不包括内嵌标签），找不到时返回 nil
+func (b *Block) Last(names ...string) *Tag {
+	for i := len(b.Tags) - 1; i >= 0; i-- {
+		if b.Tags[i].Is(names...) {
+			return b.Tags[i]
+		}
+	}
+	return nil
+}
+
+// 删除所有指定名称的标签（不包括内嵌标签）
+func (b *Block) Remove(names ...string) {
+	b.Tags = slices.DeleteFunc(b.Tags, func(tag *Tag) bool {
+		return tag.Is(names...)
+	})
+}
+
+// 对话文本中的一段内容
+type Segment struct {
+	Block *Block // 样式覆盖段，为 nil 表示普通文本
+	Text  string // 普通文本，保留转义字符
+}
+
+// 序列化文本片段
+func (s *Segment) String() string {
+	if s.Block != nil {
+		return "{" + s.Block.String() + "}"
+	}
+	return s.Text
+}
+
+// 将文本片段拼接为对话文本
+func JoinText(segments []Segment) string {
+	var b strings.Builder
+	for i := range segments {
+		b.WriteString(segments[i].String())
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package tags_test
+
+import (
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name   string
+		code   string
+		expect []*tags.Tag
+	}{
+		{
+			name: "常见标签",
+			code: `\fad(500,0)\fnB3CJROEU\fs22\frz19.65\c&H6C6D6F&\pos(468,349)`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindTag, Name: "fad", Args: []string{"500", "0"}, Paren: true, Offset: 0},
+				{Kind: tags.KindTag, Name: "fn", Args: []string{"B3CJROEU"}, Offset: 11},
+				{Kind: tags.KindTag, Name: "fs", Args: []string{"22"}, Offset: 22},
+				{Kind: tags.KindTag, Name: "frz", Args: []string{"19.65"}, Offset: 27},
+				{Kind: tags.KindTag, Name: "c", Args: []string{"&H6C6D6F&"}, Offset: 36},
+				{Kind: tags.KindTag, Name: "pos", Args: []string{"468", "349"}, Paren: true, Offset: 47},
+			},
+		},
+		{
+			name: "最长匹配",
+			code: `\fscx80\fsp-2\bord0.5\blur3\be1\b1\iclip(0,0,10,10)\i1\an8\alpha&H80&\1a&HFF&`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindTag, Name: "fscx", Args: []string{"80"}, Offset: 0},
+				{Kind: tags.KindTag, Name: "fsp", Args: []string{"-2"}, Offset: 7},
+				{Kind: tags.KindTag, Name: "bord", Args: []string{"0.5"}, Offset: 13},
+				{Kind: tags.KindTag, Name: "blur", Args: []string{"3"}, Offset: 21},
+				{Kind: tags.KindTag, Name: "be", Args: []string{"1"}, Offset: 27},
+				{Kind: tags.KindTag, Name: "b", Args: []string{"1"}, Offset: 31},
+				{Kind: tags.KindTag, Name: "iclip", Args: []string{"0", "0", "10", "10"}, Paren: true, Offset: 34},
+				{Kind: tags.KindTag, Name: "i", Args: []string{"1"}, Offset: 51},
+				{Kind: tags.KindTag, Name: "an", Args: []string{"8"}, Offset: 54},
+				{Kind: tags.KindTag, Name: "alpha", Args: []string{"&H80&"}, Offset: 58},
+				{Kind: tags.KindTag, Name: "1a", Args: []string{"&HFF&"}, Offset: 69},
+			},
+		},
+		{
+			name: "字体名包含空格与括号",
+			code: `\fn思源黑体 CN (Bold)\b0`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindTag, Name: "fn", Args: []string{"思源黑体 CN (Bold)"}, Offset: 0},
+				{Kind: tags.KindTag, Name: "b", Args: []string{"0"}, Offset: 25},
+			},
+		},
+		{
+			name: "绘图剪切",
+			code: `\clip(2,m 0 0 l 100 0 100 100)`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindTag, Name: "clip", Args: []string{"2", "m 0 0 l 100 0 100 100"}, Paren: true, Offset: 0},
+			},
+		},
+		{
+			name: "注释与未知标签",
+			code: `注释\CODE_HERE\rndx10\r`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindText, Offset: 0},
+				{Kind: tags.KindUnknown, Name: "CODE", Args: []string{"_HERE"}, Offset: 6},
+				{Kind: tags.KindTag, Name: "rndx", Args: []string{"10"}, Offset: 16},
+				{Kind: tags.KindTag, Name: "r", Args: []string{""}, Offset: 23},
+			},
+		},
+		{
+			name: "括号未闭合",
+			code: `\pos(1,2\fs20`,
+			expect: []*tags.Tag{
+				{Kind: tags.KindTag, Name: "pos", Args: []string{"1", "2"}, Paren: true, Malformed: true, Offset: 0},
+				{Kind: tags.KindTag, Name: "fs", Args: []string{"20"}, Offset: 8},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			block := tags.Parse(tc.code)
+			require.Len(t, block.Tags, len(tc.expect))
+			for i, tag := range block.Tags {
+				require.Equal(t, tc.expect[i].Kind, tag.Kind)
+				require.Equal(t, tc.expect[i].Name, tag.Name)
+				if tag.Kind != tags.KindText {
+					require.Equal(t, tc.expect[i].Args, tag.Args)
+				}
+				require.Equal(t, tc.expect[i].Paren, tag.Paren)
+				require.Equal(t, tc.expect[i].Malformed, tag.Malformed)
+				require.Equal(t, tc.expect[i].Offset, tag.Offset)
+			}
+			require.Equal(t, tc.code, block.String())
+		})
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	code := `\t(0, 500, 0.5, \fs20\clip(0,0,10,10)\t(\1c&HFF&))\bord2`
+	block := tags.Parse(code)
+	require.Len(t, block.Tags, 2)
+
+	transform := block.Tags[0]
+	require.True(t, transform.Is("t"))
+	require.Equal(t, []string{"0", "500", "0.5", `\fs20\clip(0,0,10,10)\t(\1c&HFF&)`}, transform.Args)
+	require.NotNil(t, transform.Nested)
+	require.Len(t, transform.Nested.Tags, 3)
+	require.Equal(t, 16, transform.Nested.Tags[0].Offset)
+	require.NotNil(t, transform.Nested.Tags[2].Nested)
+
+	names := []string{}
+	block.Walk(func(tag *tags.Tag) bool {
+		names = append(names, tag.Name)
+		return true
+	})
+	require.Equal(t, []string{"t", "fs", "clip", "t", "1c", "bord"}, names)
+
+	// 修改内嵌标签后重新生成
+	require.Equal(t, code, block.String())
+	transform.Nested.Tags[0].Args = []string{"30"}
+	require.Equal(t, `\t(0,500,0.5,\fs30\clip(0,0,10,10)\t(\1c&HFF&))\bord2`, block.String())
+}
+
+func TestModify(t *testing.T) {
+	block := tags.Parse(`\pos( 1 , 2 )\fs20`)
+	block.Tags[0].Args[0] = "3"
+	block.Tags = append(block.Tags, tags.NewTag("fn", "Arial"), tags.NewTag("move", "0", "0", "1", "1"))
+	require.Equal(t, `\pos(3,2)\fs20\fnArial\move(0,0,1,1)`, block.String())
+
+	block.Remove("fs", "pos")
+	require.Equal(t, `\fnArial\move(0,0,1,1)`, block.String())
+	require.Equal(t, "Arial", block.Last("fn").Arg())
+}
+
+func TestParseText(t *testing.T) {
+	testCases := []string{
+		`{\fade(500,500)}本字幕由动漫国字幕组制作(dmguo.org)\N仅供试看,请支持购买正版音像制品`,
+		`我{你甚至可以在这里写注释\CODE_HERE}能{\fn宋体\b1\i1}玻璃而{\r}伤身体\{这是转义的\n括号\}`,
+		`开始{}结束{没有闭合`,
+		`{\p1}m 0 0 l 100 0 100 100{\p0}`,
+		``,
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc, tags.JoinText(tags.ParseText(tc)))
+	}
+
+	segments := tags.ParseText(`我{\fn宋体}能\{吞\}{没有闭合`)
+	require.Len(t, segments, 3)
+	require.Equal(t, "我", segments[0].Text)
+	require.Equal(t, "宋体", segments[1].Block.Last("fn").Arg())
+	require.Equal(t, `能\{吞\}{没有闭合`, segments[2].Text)
+}
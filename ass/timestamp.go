@@ -0,0 +1,103 @@
+package ass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ASS 时间戳，单位为厘秒（1/100 秒）
+type Timestamp int64
+
+// 解析 H:MM:SS.cc 格式的时间戳
+// 与 libass 一致，各部分的位数不做限制，小数部分超过两位时四舍五入到厘秒
+func ParseTimestamp(raw string) (Timestamp, error) {
+	// 0:56:02.80
+	parts := strings.Split(strings.TrimSpace(raw), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimestamp, raw)
+	}
+	hours, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimestamp, raw)
+	}
+	minutes, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimestamp, raw)
+	}
+	seconds, ok := parseSeconds(parts[2])
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidTimestamp, raw)
+	}
+	cs := int64(hours)*360000 + int64(minutes)*6000 + seconds
+	return Timestamp(cs), nil
+}
+
+// 解析 SS[.ffff] 格式的秒数，返回四舍五入后的厘秒数
+// 只接受十进制数字，不接受符号、指数、十六进制以及 inf、NaN 等 ParseFloat 支持的写法
+func parseSeconds(raw string) (int64, bool) {
+	intPart, frac, hasFrac := strings.Cut(raw, ".")
+	seconds, err := strconv.ParseUint(intPart, 10, 32)
+	if err != nil || (hasFrac && (frac == "" || strings.Trim(frac, "0123456789") != "")) {
+		return 0, false
+	}
+	frac += "000" // 补足三位，第三位用于四舍五入
+	cs := int64(seconds)*100 + int64(frac[0]-'0')*10 + int64(frac[1]-'0')
+	if frac[2] >= '5' {
+		cs++
+	}
+	return cs, true
+}
+
+// 根据 time.Duration 创建时间戳，不足一厘秒的部分四舍五入
+func TimestampFromDuration(d time.Duration) Timestamp {
+	return Timestamp(d.Round(10*time.Millisecond) / (10 * time.Millisecond))
+}
+
+// 转换为 time.Duration
+func (t Timestamp) Duration() time.Duration {
+	return time.Duration(t) * 10 * time.Millisecond
+}
+
+// 加上一段时长，结果小于 0 时返回 0
+func (t Timestamp) Add(d time.Duration) Timestamp {
+	return max(t+TimestampFromDuration(d), 0)
+}
+
+// 返回 t-u 的时长
+func (t Timestamp) Sub(u Timestamp) time.Duration {
+	return (t - u).Duration()
+}
+
+// 格式化为 H:MM:SS.cc
+func (t Timestamp) String() string {
+	t = max(t, 0)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", t/360000, t/6000%60, t/100%60, t%100)
+}
+
+// 格式化为 SRT 使用的 HH:MM:SS,mmm
+func (t Timestamp) SRTString() string {
+	t = max(t, 0)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", t/360000, t/6000%60, t/100%60, t%100*10)
+}
+
+// 开始时间
+func (di *DialogueInfo) Start() (Timestamp, error) {
+	return ParseTimestamp(di.Fields["Start"])
+}
+
+// 结束时间
+func (di *DialogueInfo) End() (Timestamp, error) {
+	return ParseTimestamp(di.Fields["End"])
+}
+
+// 设置开始时间
+func (di *DialogueInfo) SetStart(t Timestamp) {
+	di.Fields["Start"] = t.String()
+}
+
+// 设置结束时间
+func (di *DialogueInfo) SetEnd(t Timestamp) {
+	di.Fields["End"] = t.String()
+}
@@ -0,0 +1,127 @@
+package ass_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	testCases := []struct {
+		name   string
+		raw    string
+		expect ass.Timestamp
+		str    string
+		err    bool
+	}{
+		{name: "标准格式", raw: "0:56:02.80", expect: 336280, str: "0:56:02.80"},
+		{name: "两位小时", raw: "10:00:00.01", expect: 3600001, str: "10:00:00.01"},
+		{name: "毫秒精度", raw: "0:00:01.235", expect: 124, str: "0:00:01.24"},
+		{name: "无小数", raw: "0:00:05", expect: 500, str: "0:00:05.00"},
+		{name: "缺少小时", raw: "00:05.00", err: true},
+		{name: "非数字", raw: "0:aa:05.00", err: true},
+		{name: "负数", raw: "0:00:-5.00", err: true},
+		{name: "正号", raw: "0:00:+5.00", err: true},
+		{name: "指数", raw: "0:00:5e1", err: true},
+		{name: "无穷大", raw: "0:00:inf", err: true},
+		{name: "NaN", raw: "0:00:NaN", err: true},
+		{name: "十六进制", raw: "0:00:0x1p3", err: true},
+		{name: "下划线", raw: "0:00:0_5.00", err: true},
+		{name: "空小数", raw: "0:00:05.", err: true},
+		{name: "空秒数", raw: "0:00:.50", err: true},
+		{name: "四舍五入进位", raw: "0:00:59.995", expect: 6000, str: "0:01:00.00"},
+		{name: "一位小数", raw: "0:00:01.5", expect: 150, str: "0:00:01.50"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts, err := ass.ParseTimestamp(tc.raw)
+			if tc.err {
+				require.ErrorIs(t, err, ass.ErrInvalidTimestamp)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, ts)
+			require.Equal(t, tc.str, ts.String())
+		})
+	}
+
+	ts := ass.Timestamp(336280)
+	require.Equal(t, "00:56:02,800", ts.SRTString())
+	require.Equal(t, 56*time.Minute+2800*time.Millisecond, ts.Duration())
+	require.Equal(t, ass.Timestamp(336300), ts.Add(200*time.Millisecond))
+	require.Equal(t, ass.Timestamp(0), ts.Add(-time.Hour))
+	require.Equal(t, -200*time.Millisecond, ts.Sub(336300))
+}
+
+const timingASSContent = `[Script Info]
+ScriptType: v4.00+
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,楷体,20,&H00FFFFFF,&H000000FF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,{\fad(100,200)}第一句
+Dialogue: 0,0:01:00.00,0:01:05.00,Default,,0,0,0,,{\k50}第{\k100}二{\t(0,500,\fs30)}句
+`
+
+func TestEventTiming(t *testing.T) {
+	newParser := func(t *testing.T) *ass.ASSParser {
+		ap, err := ass.NewASSParser(strings.NewReader(timingASSContent))
+		require.NoError(t, err)
+		require.NoError(t, ap.Parse())
+		return ap
+	}
+	times := func(ap *ass.ASSParser) []string {
+		result := make([]string, 0)
+		for _, di := range ap.EventTable.Events() {
+			result = append(result, di.Fields["Start"]+"-"+di.Fields["End"])
+		}
+		return result
+	}
+
+	t.Run("整体平移", func(t *testing.T) {
+		ap := newParser(t)
+		require.NoError(t, ap.EventTable.Shift(-1500*time.Millisecond))
+		require.Equal(t, []string{"0:00:00.00-0:00:01.00", "0:00:58.50-0:01:03.50"}, times(ap))
+	})
+
+	t.Run("范围平移", func(t *testing.T) {
+		ap := newParser(t)
+		require.NoError(t, ap.EventTable.ShiftRange(3000, 360000, 2*time.Second))
+		require.Equal(t, []string{"0:00:01.00-0:00:02.50", "0:01:02.00-0:01:07.00"}, times(ap))
+	})
+
+	t.Run("帧率转换", func(t *testing.T) {
+		ap := newParser(t)
+		require.NoError(t, ap.EventTable.Rescale(25, 24))
+		require.Equal(t, []string{"0:00:01.04-0:00:02.60", "0:01:02.50-0:01:07.71"}, times(ap))
+		events := ap.EventTable.Events()
+		require.Equal(t, `{\fad(104,208)}第一句`, events[0].Fields["Text"])
+		require.Equal(t, `{\k52}第{\k104}二{\t(0,521,\fs30)}句`, events[1].Fields["Text"])
+	})
+
+	t.Run("非法时间戳", func(t *testing.T) {
+		ap := newParser(t)
+		events := ap.EventTable.Events()
+		events[1].Fields["End"] = "abc"
+		require.ErrorIs(t, ap.EventTable.Shift(time.Second), ass.ErrInvalidTimestamp)
+		require.Equal(t, "0:00:01.00", events[0].Fields["Start"]) // 不做任何修改
+	})
+}
+
+func TestToSRT(t *testing.T) {
+	ap, err := ass.NewASSParser(strings.NewReader(timingASSContent))
+	require.NoError(t, err)
+	require.NoError(t, ap.Parse())
+
+	var buf bytes.Buffer
+	require.NoError(t, ap.ToSRT(&buf))
+	require.Equal(t, "1\n00:00:01,000 --> 00:00:02,500\n第一句\n\n2\n00:01:00,000 --> 00:01:05,000\n第二句\n\n", buf.String())
+}
@@ -0,0 +1,129 @@
+package ass
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/AkimioJR/assfonts-go/ass/tags"
+)
+
+// 事件的开始与结束时间
+type eventTiming struct {
+	di         *DialogueInfo
+	start, end Timestamp
+}
+
+// 解析所有事件的时间，存在非法时间戳时返回错误
+func (et *EventTable) timings() ([]eventTiming, error) {
+	timings := make([]eventTiming, 0, len(et.rows))
+	for _, di := range et.rows {
+		start, err := di.Start()
+		if err == nil {
+			var end Timestamp
+			if end, err = di.End(); err == nil {
+				timings = append(timings, eventTiming{di: di, start: start, end: end})
+				continue
+			}
+		}
+		var lineNum uint
+		if di.content != nil {
+			lineNum = di.content.LineNum
+		}
+		return nil, fmt.Errorf("invalid event timing at line %d: %w", lineNum, err)
+	}
+	return timings, nil
+}
+
+// 将所有事件平移 offset，平移后早于 0 的时间记为 0
+// 存在非法时间戳时不做任何修改并返回错误
+func (et *EventTable) Shift(offset time.Duration) error {
+	return et.ShiftRange(0, math.MaxInt64, offset)
+}
+
+// 将开始时间位于 [from, to) 之间的事件平移 offset
+// 存在非法时间戳时不做任何修改并返回错误
+func (et *EventTable) ShiftRange(from Timestamp, to Timestamp, offset time.Duration) error {
+	timings, err := et.timings()
+	if err != nil {
+		return err
+	}
+	for _, t := range timings {
+		if t.start < from || t.start >= to {
+			continue
+		}
+		t.di.SetStart(t.start.Add(offset))
+		t.di.SetEnd(t.end.Add(offset))
+	}
+	return nil
+}
+
+// 将事件时间从帧率 fromFPS 转换到 toFPS，如 25 -> 23.976
+// 卡拉 OK（\k 等）、\t、\move、\fad、\fade 中的相对时间也按相同比例缩放
+// 存在非法时间戳时不做任何修改并返回错误
+func (et *EventTable) Rescale(fromFPS float64, toFPS float64) error {
+	if fromFPS <= 0 || toFPS <= 0 {
+		return fmt.Errorf("invalid framerate %g -> %g", fromFPS, toFPS)
+	}
+	timings, err := et.timings()
+	if err != nil {
+		return err
+	}
+
+	ratio := fromFPS / toFPS
+	scale := func(t Timestamp) Timestamp {
+		return Timestamp(math.Round(float64(t) * ratio))
+	}
+	for _, t := range timings {
+		t.di.SetStart(scale(t.start))
+		t.di.SetEnd(scale(t.end))
+		if text, ok := t.di.Fields["Text"]; ok {
+			t.di.Fields["Text"] = scaleTagTimes(text, ratio)
+		}
+	}
+	return nil
+}
+
+// 按比例缩放对话文本中样式覆盖标签的相对时间
+func scaleTagTimes(text string, ratio float64) string {
+	segments := tags.ParseText(text)
+	for _, seg := range segments {
+		if seg.Block == nil {
+			continue
+		}
+		seg.Block.Walk(func(tag *tags.Tag) bool {
+			if tag.Kind != tags.KindTag {
+				return true
+			}
+			switch tag.Name {
+			case "k", "K", "kf", "ko", "kt": // 单位为厘秒
+				scaleArgs(tag, ratio, 0)
+			case "fad": // \fad(t1,t2)
+				scaleArgs(tag, ratio, 0, 1)
+			case "fade": // \fade(a1,a2,a3,t1,t2,t3,t4)
+				scaleArgs(tag, ratio, 3, 4, 5, 6)
+			case "move": // \move(x1,y1,x2,y2,t1,t2)
+				scaleArgs(tag, ratio, 4, 5)
+			case "t": // \t(t1,t2,[accel,]tags)
+				if len(tag.Args) >= 3 {
+					scaleArgs(tag, ratio, 0, 1)
+				}
+			}
+			return true
+		})
+	}
+	return tags.JoinText(segments)
+}
+
+// 缩放标签中指定位置的数值参数，无法解析的参数保持不变
+func scaleArgs(tag *tags.Tag, ratio float64, indexes ...int) {
+	for _, i := range indexes {
+		if i >= len(tag.Args) {
+			continue
+		}
+		if v, err := strconv.ParseFloat(tag.Args[i], 64); err == nil {
+			tag.Args[i] = strconv.FormatInt(int64(math.Round(v*ratio)), 10)
+		}
+	}
+}
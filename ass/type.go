@@ -10,6 +10,7 @@ type CodepointSet map[rune]struct{}
 type ContentInfo struct {
 	LineNum    uint   // 行号
 	RawContent string // 文本内容
+	eol        string // 行尾的换行符，最后一行没有换行符或不是读取得到的行时为空
 }
 
 type FormatInfo struct {
@@ -25,25 +26,94 @@ type StyleInfo struct {
 type DialogueInfo struct {
 	content    *ContentInfo      // 原始内容
 	formatInfo *FormatInfo       // 格式定义
+	comment    bool              // 是否为 Comment 行
 	Fields     map[string]string // 字段名->值的映射
 }
 
+// 是否为 Comment 行
+func (di *DialogueInfo) IsComment() bool {
+	return di.comment
+}
+
 type FontDesc struct {
 	FontName string // 字体名称
 	Bold     uint   // 字粗
 	Italic   uint   // 是否启用斜体，0->不启用
+	Charset  uint   // GDI 字符集（样式的 Encoding 字段或 \fe），0->不指定
+	Vertical bool   // 是否为竖排字体（字体名称带有前缀 @）
 }
 
 // String 返回 FontDesc 的字符串表示，用于排序
+// 指定了字符集时追加 _c<字符集>，竖排字体追加 _v
 func (fd *FontDesc) String() string {
-	return fmt.Sprintf("%s_%d_%d", fd.FontName, fd.Bold, fd.Italic)
+	s := fmt.Sprintf("%s_%d_%d", fd.FontName, fd.Bold, fd.Italic)
+	if fd.Charset != defaultCharset {
+		s += fmt.Sprintf("_c%d", fd.Charset)
+	}
+	if fd.Vertical {
+		s += "_v"
+	}
+	return s
+}
+
+// 写入嵌入字体时对原有 [Fonts] 区块的处理策略
+type FontMergePolicy uint8
+
+const (
+	FontMergeDrop    FontMergePolicy = iota // 丢弃全部原有字体
+	FontMergeKeep                           // 保留原有字体，同名的新字体不再嵌入
+	FontMergeReplace                        // 保留原有字体，同名的字体使用新字体替换
+)
+
+// 统计字符时模拟的渲染器
+type Renderer uint8
+
+const (
+	RendererNone     Renderer = iota // 不模拟渲染器，\h \n \N 均不计入字符
+	RendererLibass                   // 模拟 libass
+	RendererVSFilter                 // 模拟 VSFilter
+)
+
+// 渲染器绘制转义序列与特殊字符的规则
+type rendererProfile struct {
+	hardSpace  bool // \h 是否绘制为 U+00A0
+	softBreak  bool // \n 在 WrapStyle 不为 2 时是否绘制为空格
+	tabAsSpace bool // 制表符是否绘制为空格
+}
+
+var rendererProfiles = map[Renderer]rendererProfile{
+	RendererLibass:   {hardSpace: true, softBreak: true, tabAsSpace: true},
+	RendererVSFilter: {hardSpace: true, softBreak: true}, // VSFilter 按原字符绘制制表符
+}
+
+// 返回渲染器的绘制规则，RendererNone 与未知的渲染器不做任何替换
+func (r Renderer) profile() rendererProfile {
+	return rendererProfiles[r]
+}
+
+// 嵌入字体的结果报告
+type EmbedReport struct {
+	Added     []string // 新嵌入的字体
+	Kept      []string // 保留的原有字体
+	Replaced  []string // 被新字体替换的原有字体
+	Dropped   []string // 被丢弃的原有字体
+	Skipped   []string // 因存在同名原有字体而未嵌入的新字体
+	Malformed []uint   // 因缺少文件名而被丢弃的原有数据所在行号
+}
+
+// 统计对话字符时的状态
+type collectState struct {
+	fd        FontDesc // 当前字体描述
+	drawing   bool     // 是否处于绘图模式（\p1 等）
+	wrapStyle int      // 当前换行方式（\q 可覆盖）
 }
 
 type parseState struct {
-	inStyleSection bool // 是否在 [V4 Styles] 模块中
-	inEventSection bool // 是否在 [Events] 模块中
-	hasStyle       bool // 是否已找到 [V4 Styles] 模块
-	hasEvent       bool // 是否已找到 [Events] 模块
+	inScriptInfoSection bool // 是否在 [Script Info] 模块中
+	inStyleSection      bool // 是否在 [V4 Styles] 模块中
+	inEventSection      bool // 是否在 [Events] 模块中
+	hasStyle            bool // 是否已找到 [V4 Styles] 模块
+	hasEvent            bool // 是否已找到 [Events] 模块
 }
 
 const (
@@ -52,14 +122,28 @@ const (
 	defaultBoldFontSize = 700       // 默认粗细大小
 	defaultItalic       = 0         // 默认不斜体
 	defaultItalicSlant  = 100       // 默认斜体倾斜度
+	builtinFontName     = "Arial"   // 渲染器内置默认样式的字体名称
+	defaultCharset      = 0         // 默认字符集（ANSI）
 )
 
 var (
-	ErrStyleParseFailed   = errors.New("failed to parse style") // 未找到 [V4 Styles] 等模块
-	ErrInvalidStyleFormat = errors.New("invalid style format")  // Styles 格式解析失败
-	ErrEventParseFailed   = errors.New("failed to parse event") // 未找到 [Events] 等模块
-	ErrInvalidEventFormat = errors.New("invalid event format")  // Events 格式解析失败
-	ErrInvalidBoldValue   = errors.New("invalid bold value")    // 不合法字重值
-	ErrInvalidItalicValue = errors.New("invalid italic value")  // 不合法斜体值
-	ErrMissingFormat      = errors.New("missing format line")   // 缺少格式定义行
+	ErrStyleParseFailed    = errors.New("failed to parse style")      // 未找到 [V4 Styles] 等模块
+	ErrInvalidStyleFormat  = errors.New("invalid style format")       // Styles 格式解析失败
+	ErrEventParseFailed    = errors.New("failed to parse event")      // 未找到 [Events] 等模块
+	ErrInvalidEventFormat  = errors.New("invalid event format")       // Events 格式解析失败
+	ErrInvalidBoldValue    = errors.New("invalid bold value")         // 不合法字重值
+	ErrInvalidItalicValue  = errors.New("invalid italic value")       // 不合法斜体值
+	ErrInvalidCharsetValue = errors.New("invalid charset value")      // 不合法字符集值
+	ErrMissingFormat       = errors.New("missing format line")        // 缺少格式定义行
+	ErrInvalidUUEncode     = errors.New("invalid uuencoded data")     // UUEncode 数据不合法
+	ErrMissingFileName     = errors.New("missing embedded file name") // 嵌入数据前缺少文件名
+	ErrInvalidFileName     = errors.New("invalid embedded file name") // 嵌入文件名不合法
+	ErrInvalidColor        = errors.New("invalid color value")        // 颜色值不合法
+	ErrInvalidStyleValue   = errors.New("invalid style value")        // 样式字段值不合法
+	ErrInvalidTimestamp    = errors.New("invalid timestamp")          // 时间戳不合法
+	ErrNotParsed           = errors.New("ass content not parsed")     // 未调用 Parse 建立样式表与事件表
+	ErrStyleNotFound       = errors.New("style not found")            // 样式不存在
+	ErrFileNotFound        = errors.New("embedded file not found")    // 嵌入文件不存在
+	ErrInvalidInfoValue    = errors.New("invalid script info value")  // [Script Info] 中的值不合法
+	ErrNotSeekable         = errors.New("input is not seekable")      // 输入没有实现 io.Seeker
 )
@@ -1,12 +1,63 @@
 package ass
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// 按原始的换行符逐行写入，写入失败后忽略之后的所有写入
+type lineWriter struct {
+	w       io.Writer
+	eol     string // 新增的行使用的换行符
+	next    string // 上一行之后的换行符
+	started bool   // 是否已写入过内容
+	err     error  // 第一次写入失败的错误
+}
+
+// 写入一行，换行符在写入下一行时补上
+func (lw *lineWriter) line(s string) {
+	lw.lineWithEOL(s, "")
+}
+
+// 写入读取得到的行，并保留该行原有的换行符
+func (lw *lineWriter) content(ci ContentInfo) {
+	lw.lineWithEOL(ci.RawContent, ci.eol)
+}
+
+// 写入一行，eol 为空时使用 lw.eol
+func (lw *lineWriter) lineWithEOL(s string, eol string) {
+	if lw.err != nil {
+		return
+	}
+	if lw.started {
+		s = lw.next + s
+	}
+	lw.started = true
+	lw.next = cmp.Or(eol, lw.eol)
+	_, lw.err = io.WriteString(lw.w, s)
+}
+
+// 结束写入，finalEOL 为 true 时在末尾补上换行符
+func (lw *lineWriter) close(finalEOL bool) error {
+	if lw.err == nil && lw.started && finalEOL {
+		_, lw.err = io.WriteString(lw.w, lw.next)
+	}
+	return lw.err
+}
+
+// 返回重新生成的行对应的内容，与原始文本相同时保留原有的换行符
+func rowContent(content *ContentInfo, line string) ContentInfo {
+	if content != nil && content.RawContent == line {
+		return ContentInfo{RawContent: line, eol: content.eol}
+	}
+	return ContentInfo{RawContent: line}
+}
+
 // 判断字符串是否有前缀（不区分大小写）
 func startWith(raw string, prefix string) bool {
 	return strings.HasPrefix(strings.ToLower(raw), strings.ToLower(prefix))
@@ -41,6 +92,7 @@ func parseEventLine(content *ContentInfo, format *FormatInfo) (*DialogueInfo, er
 		content:    content,
 		Fields:     fields,
 		formatInfo: format,
+		comment:    startWith(content.RawContent, "Comment:"),
 	}
 	return di, nil
 }
@@ -87,15 +139,50 @@ func ParseDataLine(line string, format *FormatInfo) (map[string]string, error) {
 	return result, nil
 }
 
-func findTag(code []rune, pos int) (string, int) {
-	start := pos
-	for pos < len(code) {
-		if code[pos] == '\\' {
-			break
+// 计算数据行中指定字段的起始列号（按字符计，从 1 开始）
+// 找不到字段时返回 0
+func fieldColumn(line string, format *FormatInfo, field string) uint {
+	if format == nil {
+		return 0
+	}
+	fieldIdx := slices.Index(format.Fields, field)
+	colon := strings.Index(line, ":")
+	if fieldIdx < 0 || colon < 0 {
+		return 0
+	}
+
+	pos := colon + 1
+	for range fieldIdx {
+		next := strings.IndexByte(line[pos:], ',')
+		if next < 0 {
+			return 0
 		}
+		pos += next + 1
+	}
+	// 跳过字段值前的空白
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+	}
+	return uint(utf8.RuneCountInString(line[:pos])) + 1
+}
+
+// 返回 [Script Info] 中一行的值所在的列（从 1 开始），无法定位时返回 0
+func scriptInfoValueColumn(line string) uint {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return 0
+	}
+	pos := colon + 1
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
 		pos++
 	}
-	return string(code[start:pos]), pos
+	return uint(utf8.RuneCountInString(line[:pos])) + 1
+}
+
+// 去掉字体名称的前缀 @，并返回是否为竖排字体
+func splitVerticalFontName(raw string) (string, bool) {
+	fontName, vertical := strings.CutPrefix(raw, "@")
+	return fontName, vertical
 }
 
 // 根据传入的字符串判断并返回对应的“粗体”数值
@@ -141,6 +228,22 @@ func calculateItalic(raw string) (uint, error) {
 	}
 }
 
+// 根据样式的 Encoding 字段或 \fe 的参数计算字符集
+// 0 (ANSI) 与 1 (DEFAULT) 均不指定字符集，返回 0
+func calculateCharset(raw string) (uint, error) {
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return defaultCharset, err
+	}
+	if value < 0 || value > 255 {
+		return defaultCharset, ErrInvalidCharsetValue
+	}
+	if value == 1 {
+		return defaultCharset, nil
+	}
+	return uint(value), nil
+}
+
 // 将二进制数据嵌入到文本文件中
 // data：需要被嵌入的二进制内容
 // writer: 需要写入的对象
@@ -180,6 +283,38 @@ fail:
 	return fmt.Errorf("write error when UUencoding: %w", err)
 }
 
+// 将 UUEncode 编码的文本还原为二进制数据
+// data：被编码的文本，其中的换行符会被忽略
+func UUDecode(data []byte) ([]byte, error) {
+	src := make([]byte, 0, len(data))
+	for _, b := range data {
+		switch {
+		case b == '\n' || b == '\r':
+			continue // 忽略换行
+		case b < 33 || b > 33+0x3F:
+			return nil, ErrInvalidUUEncode
+		}
+		src = append(src, b-33)
+	}
+	if len(src)%4 == 1 { // 剩余 1 个字符无法还原出完整字节
+		return nil, ErrInvalidUUEncode
+	}
+
+	result := make([]byte, 0, len(src)/4*3+2)
+	for pos := 0; pos < len(src); pos += 4 {
+		group := [4]byte{0, 0, 0, 0}
+		n := copy(group[:], src[pos:min(pos+4, len(src))])
+
+		dst := [3]byte{
+			group[0]<<2 | group[1]>>4,
+			(group[1]&0xF)<<4 | group[2]>>2,
+			(group[2]&0x3)<<6 | group[3],
+		}
+		result = append(result, dst[:n-1]...)
+	}
+	return result, nil
+}
+
 // 清除ASS字幕中的特效标记，返回纯文本
 func CleanEffects(text string) string {
 	if text == "" {
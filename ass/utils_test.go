@@ -1,6 +1,7 @@
 package ass_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/AkimioJR/assfonts-go/ass"
@@ -152,3 +153,31 @@ func TestCleanEffects(t *testing.T) {
 		})
 	}
 }
+
+func TestUUDecode(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "空数据", data: []byte{}},
+		{name: "单字节", data: []byte{0xFF}},
+		{name: "两字节", data: []byte{0x00, 0x7F}},
+		{name: "三字节", data: []byte("abc")},
+		{name: "跨行数据", data: bytes.Repeat([]byte("assfonts-go"), 100)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, ass.UUEncode(tc.data, &buf, true))
+			result, err := ass.UUDecode(buf.Bytes())
+			require.NoError(t, err)
+			require.Equal(t, tc.data, result)
+		})
+	}
+
+	_, err := ass.UUDecode([]byte("!!!!!"))
+	require.ErrorIs(t, err, ass.ErrInvalidUUEncode)
+	_, err = ass.UUDecode([]byte("!! !"))
+	require.ErrorIs(t, err, ass.ErrInvalidUUEncode)
+}
@@ -3,10 +3,15 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"math"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/AkimioJR/assfonts-go/ass/lint"
 	"github.com/AkimioJR/assfonts-go/font"
 )
 
@@ -25,24 +30,107 @@ var (
 	outputASSPath         = flag.String("output", "", "Path to the input ass file")
 	customFontsDir        = flag.String("fontdir", "", "Path to the font dir in order to build database, use ',' to split it")
 	withSystemDefaultFont = flag.Bool("system", true, "Include system default fonts when building database")
+	fontMergePolicy       = flag.String("merge", "drop", "How to handle fonts already embedded in the input ass file: drop, keep or replace")
+	inputEncoding         = flag.String("encoding", "auto", "Encoding of the input ass file: auto, utf-8, utf-16le, utf-16be, gbk, big5 or shift-jis")
+	outputEncoding        = flag.String("output-encoding", "utf-8", "Encoding of the output ass file, use 'original' to keep the input encoding")
+	styleFallback         = flag.Bool("fallback", false, "Fall back to the Default style like VSFilter/libass when a dialogue uses an undefined style")
+	lintFormat            = flag.String("lint", "", "Check the input ass file instead of embedding fonts and print the report as text or json to stdout, logs are written to stderr")
+	videoDuration         = flag.Duration("duration", 0, "Video duration used by -lint to find events outside the video, e.g. 24m30s")
+	pruneStyles           = flag.Bool("prune", false, "Remove styles not used by any dialogue before embedding fonts")
+	dedupeStyles          = flag.Bool("dedupe", false, "Merge styles that are identical except for their names before -prune removes unused styles")
+	importStylesPath      = flag.String("import-styles", "", "Path to a template ass file whose styles are added to or replace the styles of the input ass file")
+	importStyleNames      = flag.String("import-names", "", "Names of the styles to import from -import-styles, use ',' to split it, all styles by default")
+	importPolicy          = flag.String("import-policy", "replace", "How to handle styles with the same name when importing styles: replace or keep")
+	importRescale         = flag.String("import-rescale", "", "Rescale imported styles when the PlayRes of the template differs: stretch, add-borders or remove-borders")
+	streamMode            = flag.Bool("stream", false, "Read the input ass file in several streaming passes instead of loading it into memory")
 )
 
+// 日志输出，-lint 时输出到 stderr，以免混入 stdout 中的检查报告
+var logOutput io.Writer = os.Stdout
+
 func logger(err error) bool {
 	switch err.(type) {
 	case *font.ErrUnsupportedPlatform, *font.ErrUnsupportedID:
 
 	case *font.InfoMsg:
-		fmt.Printf("%s[INFO]%s %s\n", ColorBlue, ColorReset, err.Error())
+		fmt.Fprintf(logOutput, "%s[INFO]%s %s\n", ColorBlue, ColorReset, err.Error())
 	case *font.WarningMsg:
-		fmt.Printf("%s[WARNING]%s %s\n", ColorYellow, ColorReset, err.Error())
+		fmt.Fprintf(logOutput, "%s[WARNING]%s %s\n", ColorYellow, ColorReset, err.Error())
 	default:
-		fmt.Printf("%s[ERROR]%s %s\n", ColorRed, ColorReset, err.Error())
+		fmt.Fprintf(logOutput, "%s[ERROR]%s %s\n", ColorRed, ColorReset, err.Error())
 	}
 	return true
 }
 
 func main() {
 	flag.Parse()
+	if *pruneStyles && *streamMode {
+		panic("-prune can not be used with -stream")
+	}
+	if *dedupeStyles && !*pruneStyles {
+		panic("-dedupe can only be used with -prune")
+	}
+	if *importStylesPath != "" && *streamMode {
+		panic("-import-styles can not be used with -stream")
+	}
+	if *importStyleNames != "" && *importStylesPath == "" {
+		panic("-import-names can only be used with -import-styles")
+	}
+	if *lintFormat != "" && *streamMode {
+		panic("-lint can not be used with -stream")
+	}
+	switch *lintFormat {
+	case "":
+	case "text", "json":
+		logOutput = os.Stderr
+	default:
+		panic(fmt.Sprintf("unknown lint format: %s", *lintFormat))
+	}
+	var policy ass.FontMergePolicy
+	switch *fontMergePolicy {
+	case "drop":
+		policy = ass.FontMergeDrop
+	case "keep":
+		policy = ass.FontMergeKeep
+	case "replace":
+		policy = ass.FontMergeReplace
+	default:
+		panic(fmt.Sprintf("unknown merge policy: %s", *fontMergePolicy))
+	}
+	inputEnc, ok := ass.ParseTextEncoding(*inputEncoding)
+	if !ok {
+		panic(fmt.Sprintf("unknown encoding: %s", *inputEncoding))
+	}
+	outputEnc := ass.EncodingAuto // 使用输入的原始编码
+	if *outputEncoding != "original" {
+		outputEnc, ok = ass.ParseTextEncoding(*outputEncoding)
+		if !ok || outputEnc == ass.EncodingAuto {
+			panic(fmt.Sprintf("unknown output encoding: %s", *outputEncoding))
+		}
+	}
+	var importOpts []ass.ImportOption
+	if *importStyleNames != "" {
+		importOpts = append(importOpts, ass.WithImportStyles(strings.Split(*importStyleNames, ",")...))
+	}
+	switch *importPolicy {
+	case "replace":
+		importOpts = append(importOpts, ass.WithStyleImportPolicy(ass.StyleImportReplace))
+	case "keep":
+		importOpts = append(importOpts, ass.WithStyleImportPolicy(ass.StyleImportKeep))
+	default:
+		panic(fmt.Sprintf("unknown import policy: %s", *importPolicy))
+	}
+	switch *importRescale {
+	case "":
+	case "stretch":
+		importOpts = append(importOpts, ass.WithImportResample(ass.AspectStretch))
+	case "add-borders":
+		importOpts = append(importOpts, ass.WithImportResample(ass.AspectAddBorders))
+	case "remove-borders":
+		importOpts = append(importOpts, ass.WithImportResample(ass.AspectRemoveBorders))
+	default:
+		panic(fmt.Sprintf("unknown rescale mode: %s", *importRescale))
+	}
 
 	db, err := font.NewFontDataBase(nil)
 	if err != nil {
@@ -68,11 +156,46 @@ func main() {
 	}
 	defer inputASS.Close()
 
-	ap, err := ass.NewASSParser(inputASS)
+	parserOpts := []ass.ParserOption{ass.WithEncoding(inputEnc)}
+	if *styleFallback {
+		parserOpts = append(parserOpts, ass.WithStyleFallback())
+	}
+	var ap *ass.ASSParser
+	if *streamMode {
+		ap, err = ass.StreamFontSets(inputASS, parserOpts...)
+	} else {
+		ap, err = ass.NewASSParser(inputASS, parserOpts...)
+		if err == nil {
+			err = ap.Parse()
+		}
+	}
 	if err != nil {
 		panic(err)
 	}
-	ap.Parse()
+	if *importStylesPath != "" {
+		importStyles(ap, importOpts)
+	}
+	if *lintFormat != "" {
+		runLint(db, ap)
+		return
+	}
+	logger(font.NewInfoMsg("input encoding: %s", ap.Encoding()))
+	for _, d := range ap.Diagnostics {
+		logger(font.NewWarningMsg("%s", d.Error()))
+	}
+	if *pruneStyles {
+		var pruneOpts []ass.PruneOption
+		if *dedupeStyles {
+			pruneOpts = append(pruneOpts, ass.WithDeduplicate())
+		}
+		pruneReport := ap.PruneStyles(pruneOpts...)
+		for _, name := range pruneReport.Removed {
+			logger(font.NewInfoMsg(`removed unused style "%s"`, name))
+		}
+		for _, from := range slices.Sorted(maps.Keys(pruneReport.Merged)) {
+			logger(font.NewInfoMsg(`merged style "%s" into "%s"`, from, pruneReport.Merged[from]))
+		}
+	}
 
 	data, err := db.Subset(ap, font.WithCheckErr(logger), font.WithConcurrent(), font.WithCheckGlyph())
 	if err != nil {
@@ -84,10 +207,95 @@ func main() {
 		panic(err)
 	}
 
-	err = ap.WriteWithEmbeddedFonts(data, outputASS)
+	var report ass.EmbedReport
+	writeOpts := []ass.WriteOption{ass.WithFontMergePolicy(policy), ass.WithEmbedReport(&report), ass.WithOutputEncoding(outputEnc)}
+	if *streamMode { // 第二遍读取输入并写出
+		if _, err = inputASS.Seek(0, io.SeekStart); err != nil {
+			panic(err)
+		}
+		r, err := ass.NewReader(inputASS, ass.WithEncoding(ap.Encoding()))
+		if err != nil {
+			panic(err)
+		}
+		err = r.WriteWithEmbeddedFonts(data, outputASS, writeOpts...)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		err = ap.WriteWithEmbeddedFonts(data, outputASS, writeOpts...)
+		if err != nil {
+			panic(err)
+		}
+	}
+	for _, name := range report.Kept {
+		logger(font.NewInfoMsg(`kept embedded font "%s"`, name))
+	}
+	for _, name := range report.Replaced {
+		logger(font.NewInfoMsg(`replaced embedded font "%s"`, name))
+	}
+	for _, lineNum := range report.Malformed {
+		logger(font.NewWarningMsg("dropped embedded font data without a file name at line %d", lineNum))
+	}
+
+	fmt.Println("success!")
+}
+
+// 使用字体数据库判断字体是否存在
+type dbFontChecker struct {
+	db *font.FontDataBase
+}
+
+func (c dbFontChecker) HasFont(name string) bool {
+	_, errValue, err := c.db.FindFont(&ass.FontDesc{FontName: name}, nil)
+	return err == nil && errValue != math.MaxInt
+}
+
+// 检查字幕并输出报告，存在错误时以状态码 1 退出
+func runLint(db *font.FontDataBase, ap *ass.ASSParser) {
+	report := lint.Lint(ap, lint.WithFontChecker(dbFontChecker{db}), lint.WithDuration(*videoDuration))
+	var err error
+	switch *lintFormat {
+	case "text":
+		err = report.WriteText(os.Stdout)
+	case "json":
+		err = report.WriteJSON(os.Stdout)
+	}
 	if err != nil {
 		panic(err)
 	}
+	if report.Failed(ass.SeverityError) {
+		os.Exit(1)
+	}
+}
 
-	fmt.Println("success!")
+// 从 -import-styles 指定的模板中导入样式
+func importStyles(ap *ass.ASSParser, opts []ass.ImportOption) {
+	templateFile, err := os.Open(*importStylesPath)
+	if err != nil {
+		panic(err)
+	}
+	defer templateFile.Close()
+	template, err := ass.NewASSParser(templateFile)
+	if err != nil {
+		panic(err)
+	}
+
+	report, err := ap.ImportStyles(template, opts...)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range report.Added {
+		logger(font.NewInfoMsg(`imported style "%s"`, name))
+	}
+	for _, name := range report.Replaced {
+		logger(font.NewWarningMsg(`style "%s" conflicts with the template, replaced`, name))
+	}
+	for _, name := range report.Skipped {
+		logger(font.NewWarningMsg(`style "%s" conflicts with the template, kept`, name))
+	}
+	srcX, srcY := template.ScriptInfo.PlayRes()
+	dstX, dstY := ap.ScriptInfo.PlayRes()
+	if !report.Resampled && (srcX != dstX || srcY != dstY) {
+		logger(font.NewWarningMsg("PlayRes of the template (%dx%d) differs from the input (%dx%d), use -import-rescale to rescale imported styles", srcX, srcY, dstX, dstY))
+	}
 }
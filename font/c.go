@@ -14,6 +14,11 @@ package font
 // harfbuzz
 #define HB_EXPERIMENTAL_API
 #include <hb-subset.h>
+
+// 子集化 API 自 3.0.0 起稳定，竖排字体依赖该版本起默认保留的竖排特性与度量表
+#if !HB_VERSION_ATLEAST(3, 0, 0)
+#error "HarfBuzz 3.0.0 or later is required"
+#endif
 */
 import "C"
 import (
@@ -155,9 +160,10 @@ func (lib *FreeTypeLibrary) parseFace(face C.FT_Face, fn func(error) bool) (*Fon
 	}
 
 	fontFaceInfo := FontFaceInfo{
-		Name:   fontNmae,               // 字体名称信息
-		Weight: getAssFaceWeight(face), // 字重
-		Slant:  getAssFaceSlant(face),  // 0或110，斜体角度
+		Name:          fontNmae,                   // 字体名称信息
+		Weight:        getAssFaceWeight(face),     // 字重
+		Slant:         getAssFaceSlant(face),      // 0或110，斜体角度
+		CodePageRange: getFaceCodePageRange(face), // 支持的代码页
 	}
 	return &fontFaceInfo, nil
 }
@@ -308,6 +314,19 @@ func getAssFaceSlant(face C.FT_Face) uint {
 	return uint(slant)
 }
 
+// 获取 OS/2 表中的代码页范围，OS/2 表不存在或版本低于 1 时返回全 0
+func getFaceCodePageRange(face C.FT_Face) [2]uint32 {
+	os2 := C.FT_Get_Sfnt_Table(face, C.FT_SFNT_OS2) // 获取OS/2表
+	if os2 == nil {
+		return [2]uint32{}
+	}
+	table := (*C.TT_OS2)(os2)
+	if table.version < 1 { // ulCodePageRange 从版本 1 开始才存在
+		return [2]uint32{}
+	}
+	return [2]uint32{uint32(table.ulCodePageRange1), uint32(table.ulCodePageRange2)}
+}
+
 func (db *FontDataBase) CheckGlyph(fontData []byte, source *FontFaceLocation, fontSet ass.CodepointSet, fontDesc *ass.FontDesc) error {
 	var missingCodepoints []rune
 	var face C.FT_Face
@@ -335,6 +354,8 @@ func (db *FontDataBase) CheckGlyph(fontData []byte, source *FontFaceLocation, fo
 	return nil
 }
 
+// 子集化字体
+// HarfBuzz 3.0.0 起默认保留 vert/vrt2 等竖排替换特性以及 vhea/vmtx/VORG 竖排度量表，竖排字体无需额外设置
 func (db *FontDataBase) CreatSubfont(subsetFontInfo *SubsetFontInfo, fontData []byte) ([]byte, error) {
 	cFontData := C.CBytes(fontData)
 	defer C.free(cFontData)
@@ -0,0 +1,132 @@
+package font
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsCharset(t *testing.T) {
+	gb2312 := [2]uint32{1 << 18, 0}     // 936 Chinese Simplified
+	symbol := [2]uint32{1 << 31, 0}     // Symbol Character Set
+	latin2 := [2]uint32{1<<0 | 1<<1, 0} // 1252 Latin 1 与 1250 Latin 2
+
+	tests := []struct {
+		name          string
+		codePageRange [2]uint32
+		charset       uint
+		want          bool
+	}{
+		{"未指定字符集", gb2312, 0, true},
+		{"没有代码页信息", [2]uint32{}, 134, true},
+		{"未知字符集", gb2312, 1, true},
+		{"支持简体中文", gb2312, 134, true},
+		{"不支持繁体中文", gb2312, 136, false},
+		{"支持符号字符集", symbol, 2, true},
+		{"不支持符号字符集", gb2312, 2, false},
+		{"支持东欧", latin2, 238, true},
+		{"不支持俄文", latin2, 204, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &FontFaceInfo{CodePageRange: tt.codePageRange}
+			require.Equal(t, tt.want, supportsCharset(info, tt.charset))
+		})
+	}
+}
+
+func TestCharsetCodePageBits(t *testing.T) {
+	bits := make([]uint, 0, len(charsetCodePageBits))
+	for charset, bit := range charsetCodePageBits {
+		require.NotZero(t, charset, "DEFAULT_CHARSET 不对应任何代码页")
+		require.Less(t, bit, uint(64))
+		bits = append(bits, bit)
+	}
+	slices.Sort(bits)
+	require.Len(t, slices.Compact(bits), len(charsetCodePageBits), "每个字符集对应不同的代码页")
+}
+
+// 同一家族的简体、繁体与粗体字体
+var charsetFontData = map[string][]FontFaceInfo{
+	"/fonts/KaiSC.ttf": {{
+		Source:        FontFaceLocation{Path: "/fonts/KaiSC.ttf"},
+		Name:          FontName{FamilyNames: []string{"楷体"}},
+		Weight:        400,
+		CodePageRange: [2]uint32{1 << 18, 0},
+	}},
+	"/fonts/KaiTC.ttf": {{
+		Source:        FontFaceLocation{Path: "/fonts/KaiTC.ttf"},
+		Name:          FontName{FamilyNames: []string{"楷体"}},
+		Weight:        500,
+		CodePageRange: [2]uint32{1 << 20, 0},
+	}},
+	"/fonts/KaiBold.ttf": {{
+		Source:        FontFaceLocation{Path: "/fonts/KaiBold.ttf"},
+		Name:          FontName{FamilyNames: []string{"楷体"}},
+		Weight:        700,
+		CodePageRange: [2]uint32{1 << 18, 0},
+	}},
+}
+
+func TestFindFontCharset(t *testing.T) {
+	db := &FontDataBase{data: charsetFontData}
+
+	tests := []struct {
+		name    string
+		desc    ass.FontDesc
+		want    string
+		wantErr int
+	}{
+		{"未指定字符集", ass.FontDesc{FontName: "楷体", Bold: 400}, "/fonts/KaiSC.ttf", 0},
+		{"简体中文", ass.FontDesc{FontName: "楷体", Bold: 400, Charset: 134}, "/fonts/KaiSC.ttf", 0},
+		{"繁体中文", ass.FontDesc{FontName: "楷体", Bold: 400, Charset: 136}, "/fonts/KaiTC.ttf", 100},
+		{"字符集优先于字重", ass.FontDesc{FontName: "楷体", Bold: 700, Charset: 136}, "/fonts/KaiTC.ttf", 200},
+		{"没有支持的字体时仍按字重选择", ass.FontDesc{FontName: "楷体", Bold: 700, Charset: 128}, "/fonts/KaiBold.ttf", charsetPenalty},
+		{"字重误差最大时字符集仍然优先", ass.FontDesc{FontName: "楷体", Bold: 2000, Italic: 100, Charset: 136}, "/fonts/KaiTC.ttf", 500 + 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, errValue, err := db.FindFont(&tt.desc, nil)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, source.Path)
+			require.Equal(t, tt.wantErr, errValue)
+		})
+	}
+}
+
+func TestParseSubsetFontInfosMerge(t *testing.T) {
+	db := &FontDataBase{data: charsetFontData}
+	ap := &ass.ASSParser{FontSets: map[ass.FontDesc]ass.CodepointSet{
+		{FontName: "楷体", Bold: 400}:                 {'一': {}},
+		{FontName: "楷体", Bold: 400, Charset: 134}:   {'二': {}},
+		{FontName: "楷体", Bold: 400, Vertical: true}: {'三': {}},
+		{FontName: "楷体", Bold: 400, Italic: 100}:    {'四': {}}, // 斜体与正体使用同一字体，仍单独生成子集
+		{FontName: "楷体", Bold: 400, Charset: 136}:   {'五': {}}, // 繁体使用另一字体
+	}}
+
+	sfis, err := db.parseSubsetFontInfos(ap, nil)
+	require.NoError(t, err)
+	slices.SortFunc(sfis, func(a, b SubsetFontInfo) int {
+		return strings.Compare(a.Source.Path+a.FontsDesc.String(), b.Source.Path+b.FontsDesc.String())
+	})
+	require.Len(t, sfis, 3)
+
+	require.Equal(t, "/fonts/KaiSC.ttf", sfis[0].Source.Path)
+	require.Equal(t, ass.FontDesc{FontName: "楷体", Bold: 400, Vertical: true}, sfis[0].FontsDesc)
+	for _, ch := range "一二三A" {
+		require.Contains(t, sfis[0].Codepoints, ch)
+	}
+	require.NotContains(t, sfis[0].Codepoints, '四')
+
+	require.Equal(t, "/fonts/KaiSC.ttf", sfis[1].Source.Path)
+	require.Equal(t, ass.FontDesc{FontName: "楷体", Bold: 400, Italic: 100}, sfis[1].FontsDesc)
+	require.Contains(t, sfis[1].Codepoints, '四')
+	require.NotContains(t, sfis[1].Codepoints, '一')
+
+	require.Equal(t, "/fonts/KaiTC.ttf", sfis[2].Source.Path)
+	require.Equal(t, ass.FontDesc{FontName: "楷体", Bold: 400}, sfis[2].FontsDesc)
+	require.Contains(t, sfis[2].Codepoints, '五')
+}
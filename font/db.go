@@ -241,8 +241,16 @@ func (db *FontDataBase) subset(sfi *SubsetFontInfo, c *subsetConfig) (string, []
 	return sfi.FontsDesc.String() + filepath.Ext(sfi.Source.Path), subFontData, nil
 }
 
+// 合并使用同一字体且只有字符集或横竖排（\fn@）不同的子集化信息
+// 这些描述对应同一个字体，分开子集化时内部名称相同，渲染器只会加载其中一个而导致字形缺失
+// 字符集只用于查找字体，不体现在子集名称中；存在竖排描述时合并后的子集标记为竖排
 func (db *FontDataBase) parseSubsetFontInfos(ap *ass.ASSParser, fn CheckErrFn) ([]SubsetFontInfo, error) {
+	type subsetKey struct {
+		source FontFaceLocation
+		desc   ass.FontDesc // 去掉字符集与竖排标记的字体描述
+	}
 	subsetFontInfos := make([]SubsetFontInfo, 0, len(ap.FontSets))
+	index := make(map[subsetKey]int) // 合并键->在 subsetFontInfos 中的位置
 
 	for fontDesc, fontSet := range ap.FontSets {
 		// fmt.Println(fontDesc)
@@ -252,7 +260,18 @@ func (db *FontDataBase) parseSubsetFontInfos(ap *ass.ASSParser, fn CheckErrFn) (
 			return nil, err
 		}
 		if fn != nil {
-			fn(NewInfoMsg(`"%s" (%d,%d) ---> "%s"[%d], error value: %d`, fontDesc.FontName, fontDesc.Bold, fontDesc.Italic, fontPath.Path, fontPath.Index, errValue))
+			fn(NewInfoMsg(`"%s" (%d,%d,%d) ---> "%s"[%d], error value: %d`, fontDesc.FontName, fontDesc.Bold, fontDesc.Italic, fontDesc.Charset, fontPath.Path, fontPath.Index, errValue))
+		}
+
+		key := subsetKey{source: *fontPath, desc: fontDesc}
+		key.desc.Charset, key.desc.Vertical = 0, false
+		if i, ok := index[key]; ok {
+			sfi := &subsetFontInfos[i]
+			for wch := range fontSet {
+				sfi.Codepoints[wch] = struct{}{}
+			}
+			sfi.FontsDesc.Vertical = sfi.FontsDesc.Vertical || fontDesc.Vertical
+			continue
 		}
 
 		for wch := range fontSet {
@@ -261,8 +280,11 @@ func (db *FontDataBase) parseSubsetFontInfos(ap *ass.ASSParser, fn CheckErrFn) (
 		for _, ch := range additionalCodepoints {
 			codepointSet[ch] = struct{}{}
 		}
+		desc := key.desc
+		desc.Vertical = fontDesc.Vertical
+		index[key] = len(subsetFontInfos)
 		subsetFontInfos = append(subsetFontInfos, SubsetFontInfo{
-			FontsDesc:  fontDesc,
+			FontsDesc:  desc,
 			Codepoints: codepointSet,
 			Source:     *fontPath,
 		})
@@ -270,6 +292,15 @@ func (db *FontDataBase) parseSubsetFontInfos(ap *ass.ASSParser, fn CheckErrFn) (
 	return subsetFontInfos, nil
 }
 
+const (
+	maxFontWeight = 1000 // 计算误差时字重的上限，与 GDI 一致
+	maxFontSlant  = 110  // 计算误差时斜体的上限，即字体斜体的倾斜角度
+
+	// 字体不支持指定字符集时增加的误差
+	// 字体的字重为 100-900、倾斜角度为 0 或 110，截断后的误差之和不超过 maxFontWeight+maxFontSlant
+	charsetPenalty = maxFontWeight + maxFontSlant + 1
+)
+
 var (
 	ttfExts = []string{".ttf", ".ttc"}
 	otfExts = []string{".otf", ".otc"}
@@ -294,13 +325,17 @@ func (db *FontDataBase) FindFont(fontDesc *ass.FontDesc, fontSet ass.CodepointSe
 					found = true
 					currentErr = 0
 				} else if slices.Contains(fontFaceInfo.Name.FamilyNames, targetName) { // 检查家族名
-					currentErr = abs(int(fontDesc.Bold)-int(fontFaceInfo.Weight)) + abs(int(fontDesc.Italic)-int(fontFaceInfo.Slant))
+					currentErr = abs(int(min(fontDesc.Bold, maxFontWeight))-int(fontFaceInfo.Weight)) +
+						abs(int(min(fontDesc.Italic, maxFontSlant))-int(fontFaceInfo.Slant))
 					found = true
 				}
 
 				if !found {
 					continue
 				}
+				if !supportsCharset(&fontFaceInfo, fontDesc.Charset) { // 与 GDI 一致，优先选择支持字符集的字体
+					currentErr += charsetPenalty
+				}
 				if currentErr < minErr {
 					minErr = currentErr
 					best = &fontFaceInfo.Source
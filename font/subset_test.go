@@ -0,0 +1,105 @@
+package font
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/AkimioJR/assfonts-go/ass"
+	"github.com/stretchr/testify/require"
+)
+
+// 返回字体中的所有表，data 为字体集合（TTC）时按 index 选择字体，不是 OpenType 字体时返回 nil
+func sfntTables(data []byte, index uint) map[string][]byte {
+	if len(data) < 12 {
+		return nil
+	}
+	offset := 0
+	if string(data[:4]) == "ttcf" {
+		if index >= uint(binary.BigEndian.Uint32(data[8:12])) || len(data) < 16+4*int(index) {
+			return nil
+		}
+		offset = int(binary.BigEndian.Uint32(data[12+4*index:]))
+	}
+	if len(data) < offset+12 {
+		return nil
+	}
+	numTables := int(binary.BigEndian.Uint16(data[offset+4:]))
+	if len(data) < offset+12+16*numTables {
+		return nil
+	}
+	tables := make(map[string][]byte, numTables)
+	for i := range numTables {
+		record := data[offset+12+16*i:]
+		start := int(binary.BigEndian.Uint32(record[8:12]))
+		end := start + int(binary.BigEndian.Uint32(record[12:16]))
+		if end > len(data) {
+			return nil
+		}
+		tables[string(record[:4])] = data[start:end]
+	}
+	return tables
+}
+
+// 返回 GSUB 表中的特性标签
+func gsubFeatures(gsub []byte) map[string]bool {
+	features := make(map[string]bool)
+	if len(gsub) < 10 || int(binary.BigEndian.Uint16(gsub[6:8]))+2 > len(gsub) {
+		return features
+	}
+	featureList := gsub[binary.BigEndian.Uint16(gsub[6:8]):]
+	count := int(binary.BigEndian.Uint16(featureList[:2]))
+	for i := range min(count, (len(featureList)-2)/6) {
+		features[string(featureList[2+6*i:6+6*i])] = true
+	}
+	return features
+}
+
+// 竖排字体的子集保留 vert 特性与竖排度量表
+func TestCreatSubfontVertical(t *testing.T) {
+	db, err := NewFontDataBase(nil)
+	require.NoError(t, err)
+	defer db.Close()
+	if err := db.BuildDB([]string{"../test_case/fonts"}, true, nil); errors.Is(err, ErrNoFontFileFound) {
+		t.Skip("no font file found")
+	} else {
+		require.NoError(t, err)
+	}
+
+	// 查找带有 vert 特性与 vmtx 表的字体
+	var source *FontFaceLocation
+	var fontData []byte
+	for path, infos := range db.data {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			tables := sfntTables(data, info.Source.Index)
+			if _, ok := tables["vmtx"]; ok && gsubFeatures(tables["GSUB"])["vert"] {
+				source, fontData = &info.Source, data
+				break
+			}
+		}
+		if source != nil {
+			break
+		}
+	}
+	if source == nil {
+		t.Skip("no font with vertical layout data found")
+	}
+
+	sfi := &SubsetFontInfo{
+		FontsDesc:  ass.FontDesc{FontName: "vertical", Bold: 400, Vertical: true},
+		Codepoints: ass.CodepointSet{'一': {}, '（': {}, '）': {}, '「': {}, '」': {}, '。': {}},
+		Source:     *source,
+	}
+	subset, err := db.CreatSubfont(sfi, fontData)
+	require.NoError(t, err)
+
+	tables := sfntTables(subset, 0)
+	require.Contains(t, tables, "vhea")
+	require.Contains(t, tables, "vmtx")
+	require.True(t, gsubFeatures(tables["GSUB"])["vert"], "GSUB vert feature is dropped")
+}
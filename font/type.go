@@ -32,11 +32,12 @@ type FontName struct {
 }
 
 type FontFaceInfo struct {
-	Source   FontFaceLocation `json:"source"`   // 字体来源信息
-	Name     FontName         `json:"name"`     // 字体名称信息
-	Weight   uint             `json:"weight"`   // 字重
-	Slant    uint             `json:"slant"`    // 倾斜角度
-	Modified time.Time        `json:"modified"` // 字体文件最后修改时间
+	Source        FontFaceLocation `json:"source"`          // 字体来源信息
+	Name          FontName         `json:"name"`            // 字体名称信息
+	Weight        uint             `json:"weight"`          // 字重
+	Slant         uint             `json:"slant"`           // 倾斜角度
+	CodePageRange [2]uint32        `json:"code_page_range"` // OS/2 表中支持的代码页，全为 0 表示未知
+	Modified      time.Time        `json:"modified"`        // 字体文件最后修改时间
 }
 type SubsetFontInfo struct {
 	FontsDesc  ass.FontDesc     // 字体描述列表
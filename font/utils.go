@@ -119,3 +119,37 @@ func formatCodepoints(codepoints []rune) string {
 	}
 	return b.String()
 }
+
+// GDI 字符集 -> OS/2 ulCodePageRange 中对应的位
+var charsetCodePageBits = map[uint]uint{
+	238: 1,  // EASTEUROPE_CHARSET -> 1250 Latin 2
+	204: 2,  // RUSSIAN_CHARSET -> 1251 Cyrillic
+	161: 3,  // GREEK_CHARSET -> 1253 Greek
+	162: 4,  // TURKISH_CHARSET -> 1254 Turkish
+	177: 5,  // HEBREW_CHARSET -> 1255 Hebrew
+	178: 6,  // ARABIC_CHARSET -> 1256 Arabic
+	186: 7,  // BALTIC_CHARSET -> 1257 Baltic
+	163: 8,  // VIETNAMESE_CHARSET -> 1258 Vietnamese
+	222: 16, // THAI_CHARSET -> 874 Thai
+	128: 17, // SHIFTJIS_CHARSET -> 932 JIS/Japan
+	134: 18, // GB2312_CHARSET -> 936 Chinese Simplified
+	129: 19, // HANGUL_CHARSET -> 949 Korean Wansung
+	136: 20, // CHINESEBIG5_CHARSET -> 950 Chinese Traditional
+	130: 21, // JOHAB_CHARSET -> 1361 Korean Johab
+	77:  29, // MAC_CHARSET -> Macintosh Character Set
+	255: 30, // OEM_CHARSET -> OEM Character Set
+	2:   31, // SYMBOL_CHARSET -> Symbol Character Set
+}
+
+// 判断字体是否支持指定的字符集
+// 未指定字符集、字符集未知或字体没有代码页信息时均视为支持
+func supportsCharset(info *FontFaceInfo, charset uint) bool {
+	if charset == 0 || info.CodePageRange == [2]uint32{} {
+		return true
+	}
+	bit, ok := charsetCodePageBits[charset]
+	if !ok {
+		return true
+	}
+	return info.CodePageRange[bit/32]&(1<<(bit%32)) != 0
+}